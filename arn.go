@@ -0,0 +1,58 @@
+package awssecret
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/pkg/errors"
+)
+
+// IsSecretARN reports whether s is a well-formed AWS Secrets Manager secret
+// ARN, such as
+// arn:aws:secretsmanager:us-east-1:123456789012:secret:prod/db-AbCdEf.
+//
+func IsSecretARN(s string) bool {
+	a, err := arn.Parse(s)
+	if err != nil {
+		return false
+	}
+	return a.Service == "secretsmanager" &&
+		a.Region != "" &&
+		isAccountID(a.AccountID) &&
+		strings.HasPrefix(a.Resource, "secret:") &&
+		len(a.Resource) > len("secret:")
+}
+
+// secretARNRegion returns the region named in a secret ARN, or "" if
+// secretID is a friendly name rather than an ARN.
+//
+func secretARNRegion(secretID string) string {
+	if !IsSecretARN(secretID) {
+		return ""
+	}
+	a, _ := arn.Parse(secretID)
+	return a.Region
+}
+
+func isAccountID(s string) bool {
+	if len(s) != 12 {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// validateSecretID fails fast on secret IDs which are meant to be ARNs
+// (because they start with "arn:") but aren't well-formed. Friendly names
+// are accepted as-is.
+//
+func validateSecretID(secretID string) error {
+	if strings.HasPrefix(secretID, "arn:") && !IsSecretARN(secretID) {
+		return errors.Wrapf(ErrMalformedARN, "Invalid secret ID %q", secretID)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package awssecret
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestIsSecretARN(t *testing.T) {
+	tests := []struct {
+		s    string
+		want bool
+	}{
+		{"arn:aws:secretsmanager:us-east-1:123456789012:secret:prod/db-AbCdEf", true},
+		{"arn:aws-cn:secretsmanager:cn-north-1:123456789012:secret:db-AbCdEf", true},
+		{"arn:aws-us-gov:secretsmanager:us-gov-west-1:123456789012:secret:db", true},
+		{"prod/db", false},
+		{"", false},
+		{"arn:aws:secretsmanager:us-east-1:123456789012", false},
+		{"arn:aws:ssm:us-east-1:123456789012:parameter/db", false},
+		{"arn:aws:secretsmanager::123456789012:secret:db", false},
+		{"arn:aws:secretsmanager:us-east-1:1234:secret:db", false},
+		{"arn:aws:secretsmanager:us-east-1:12345678901a:secret:db", false},
+		{"arn:aws:secretsmanager:us-east-1:123456789012:db", false},
+		{"arn:aws:secretsmanager:us-east-1:123456789012:secret:", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			if got := IsSecretARN(tt.s); got != tt.want {
+				t.Errorf("IsSecretARN(%q) = %v, want %v", tt.s, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMalformedARNFailsFast(t *testing.T) {
+	tests := []struct {
+		secretID  string
+		wantErr   error
+		wantCalls int
+	}{
+		{"arn:aws:secretsmanager:us-east-1:123456789012:secret:db-AbCdEf", nil, 1},
+		{"db", nil, 1},
+		{"arn:aws:secretsmanager:us-east-1:123456789012", ErrMalformedARN, 0},
+		{"arn:aws:secretsmanager:us-east-1:123456789012:secret:", ErrMalformedARN, 0},
+		{"arn:", ErrMalformedARN, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.secretID, func(t *testing.T) {
+			stub := newStringStub(map[string]string{tt.secretID: "hunter2"})
+			_, err := GetStringSecretWithClient(stub, tt.secretID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if n := stub.calls(); n != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", n, tt.wantCalls)
+			}
+		})
+	}
+}
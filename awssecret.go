@@ -1,11 +1,12 @@
 package awssecret
 
 import (
+	"context"
 	"encoding/json"
+	"strconv"
 	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/secretsmanager"
 	"github.com/pkg/errors"
@@ -24,25 +25,21 @@ type APICredential struct {
 // in a JSON format.
 //
 func GetAPICredentialSecret(sess *session.Session, secretName string) (cred *APICredential, err error) {
-	var secret string
-	cred = &APICredential{}
-	secret, err = GetStringSecret(sess, secretName)
-	if err != nil {
-		return cred, errors.Wrapf(err, "Couldn't build credential. Failed to retrieve secret.")
-	}
-
-	err = json.Unmarshal([]byte(secret), cred)
-	if err != nil {
-		return cred, errors.Wrapf(err, "Couldn't build credential. Failed to decode JSON.")
-	}
+	return GetAPICredentialSecretWithContext(context.Background(), sess, secretName)
+}
 
-	return cred, nil
+// GetAPICredentialSecretWithContext is the same as GetAPICredentialSecret, but
+// uses the supplied context for the underlying AWS request.
+//
+func GetAPICredentialSecretWithContext(ctx context.Context, sess *session.Session, secretName string) (cred *APICredential, err error) {
+	return getJSONSecretAs[APICredential](ctx, clientFor(sess), secretName, JSONOptions{}, "Couldn't build credential")
 }
 
 // Credential represents a generic kind of credential stored in AWS
 // Secrets Manager
 //
 type Credential struct {
+	Engine   string `json:"engine"`
 	Host     string `json:"host"`
 	Port     int    `json:"port"`
 	Key      string `json:"key"`
@@ -55,30 +52,60 @@ type Credential struct {
 // in a JSON format.
 //
 func GetCredentialSecret(sess *session.Session, secretName string) (cred *Credential, err error) {
-	var secret string
-	cred = &Credential{}
-	secret, err = GetStringSecret(sess, secretName)
+	return GetCredentialSecretWithContext(context.Background(), sess, secretName)
+}
+
+// GetCredentialSecretWithContext is the same as GetCredentialSecret, but
+// uses the supplied context for the underlying AWS request.
+//
+func GetCredentialSecretWithContext(ctx context.Context, sess *session.Session, secretName string) (cred *Credential, err error) {
+	return getJSONSecretAs[Credential](ctx, clientFor(sess), secretName, JSONOptions{}, "Couldn't build credential")
+}
+
+// GetCredentialRaw retrieves the named secret from AWS Secrets Manager and
+// returns both the decoded Credential and the raw secret string. If the
+// secret isn't JSON at all (e.g. it's already a DSN), cred is nil and no
+// error is returned, leaving the caller to decide what to do with raw.
+//
+func GetCredentialRaw(sess *session.Session, secretName string) (cred *Credential, raw string, err error) {
+	return GetCredentialRawWithContext(context.Background(), sess, secretName)
+}
+
+// GetCredentialRawWithContext is the same as GetCredentialRaw, but uses the
+// supplied context for the underlying AWS request.
+//
+func GetCredentialRawWithContext(ctx context.Context, sess *session.Session, secretName string) (cred *Credential, raw string, err error) {
+	raw, err = GetStringSecretWithContext(ctx, sess, secretName)
 	if err != nil {
-		return cred, errors.Wrapf(err, "Couldn't build credential. Failed to retrieve secret.")
+		return nil, "", errors.Wrapf(err, "Couldn't build credential. Failed to retrieve secret.")
 	}
 
-	err = json.Unmarshal([]byte(secret), cred)
+	if !json.Valid([]byte(raw)) {
+		return nil, raw, nil
+	}
+
+	cred = &Credential{}
+	err = Unmarshal([]byte(raw), cred)
 	if err != nil {
-		return cred, errors.Wrapf(err, "Couldn't build credential. Failed to decode JSON.")
+		return nil, raw, errors.Wrapf(err, "Couldn't build credential. Failed to decode JSON.")
 	}
 
-	return cred, nil
+	return cred, raw, nil
 }
 
 type dsn struct {
-	Engine               string `json:"engine"`
-	Host                 string `json:"host"`
-	DBName               string `json:"dbname"`
-	Username             string `json:"username"`
-	Password             string `json:"password"`
-	Port                 int    `json:"port"`
-	SearchPath           string `json:"search_path"`
-	DBInstanceIdentifier string `json:"dbInstanceIdentifier"`
+	Engine               string      `json:"engine"`
+	Host                 string      `json:"host"`
+	DBName               string      `json:"dbname"`
+	Username             string      `json:"username"`
+	Password             string      `json:"password"`
+	Port                 int         `json:"port"`
+	SearchPath           string      `json:"search_path"`
+	DBInstanceIdentifier string      `json:"dbInstanceIdentifier"`
+	SSLMode              string      `json:"sslmode"`
+	SSLRootCert          string      `json:"sslrootcert"`
+	ConnectTimeout       json.Number `json:"connect_timeout"`
+	ApplicationName      string      `json:"application_name"`
 }
 
 // GetPostgresDSNSecret retrieves the named secret from AWS Secrets Manager
@@ -86,22 +113,43 @@ type dsn struct {
 // DSN string
 //
 func GetPostgresDSNSecret(sess *session.Session, secretName string) (dsnStr string, err error) {
-	str, err := GetStringSecret(sess, secretName)
-	if err != nil {
-		return "", errors.Wrapf(err, "Couldn't build DSN. Failed to retrieve secret")
-	}
+	return GetPostgresDSNSecretWithContext(context.Background(), sess, secretName)
+}
 
+// GetPostgresDSNSecretWithContext is the same as GetPostgresDSNSecret, but
+// uses the supplied context for the underlying AWS request.
+//
+func GetPostgresDSNSecretWithContext(ctx context.Context, sess *session.Session, secretName string) (dsnStr string, err error) {
+	return clientFor(sess).GetPostgresDSNWithContext(ctx, secretName)
+}
+
+// postgresDSN converts a secret from the JSON its natively stored as into a
+// Postgres-compatible DSN string, or returns it unchanged if it already
+// looks like a key=value DSN or a postgres:// URL.
+//
+func postgresDSN(str string) (dsnStr string, err error) {
 	// If the string already looks like a DSN, just return it
 	if strings.Index(str, "host=") >= 0 && strings.Index(str, "dbname=") >= 0 {
 		return str, nil
 	}
 
+	// Likewise if it's already a connection URL
+	if strings.HasPrefix(str, "postgres://") || strings.HasPrefix(str, "postgresql://") {
+		return str, nil
+	}
+
 	d := dsn{}
-	err = json.Unmarshal([]byte(str), &d)
+	err = Unmarshal([]byte(str), &d)
 	if err != nil {
 		return str, err
 	}
 
+	return d.postgres(), nil
+}
+
+// postgres formats the DSN in the libpq key=value form.
+//
+func (d dsn) postgres() string {
 	s := strings.Builder{}
 	if d.Host != "" {
 		s.WriteString("host=")
@@ -109,6 +157,12 @@ func GetPostgresDSNSecret(sess *session.Session, secretName string) (dsnStr stri
 		s.WriteString(" ")
 	}
 
+	if d.Port != 0 {
+		s.WriteString("port=")
+		s.WriteString(strconv.Itoa(d.Port))
+		s.WriteString(" ")
+	}
+
 	if d.DBName != "" {
 		s.WriteString("dbname=")
 		s.WriteString(d.DBName)
@@ -133,41 +187,132 @@ func GetPostgresDSNSecret(sess *session.Session, secretName string) (dsnStr stri
 		s.WriteString(" ")
 	}
 
-	return s.String(), nil
+	if d.SSLMode != "" {
+		s.WriteString("sslmode=")
+		s.WriteString(d.SSLMode)
+		s.WriteString(" ")
+	}
+
+	if d.SSLRootCert != "" {
+		s.WriteString("sslrootcert=")
+		s.WriteString(d.SSLRootCert)
+		s.WriteString(" ")
+	}
+
+	if d.ConnectTimeout != "" {
+		s.WriteString("connect_timeout=")
+		s.WriteString(d.ConnectTimeout.String())
+		s.WriteString(" ")
+	}
+
+	if d.ApplicationName != "" {
+		s.WriteString("application_name=")
+		s.WriteString(quotePostgresValue(d.ApplicationName))
+		s.WriteString(" ")
+	}
+
+	return s.String()
+}
+
+// quotePostgresValue single-quotes a libpq key=value DSN value if it
+// contains spaces, escaping any quotes and backslashes within it.
+//
+func quotePostgresValue(v string) string {
+	if !strings.ContainsAny(v, " '\\") {
+		return v
+	}
+	v = strings.ReplaceAll(v, `\`, `\\`)
+	v = strings.ReplaceAll(v, `'`, `\'`)
+	return "'" + v + "'"
 }
 
 // GetStringSecret retrieves the named secret from AWS Secrets Manager and
 // returns it in its raw form
 //
 func GetStringSecret(sess *session.Session, secretName string) (secret string, err error) {
+	return GetStringSecretWithContext(context.Background(), sess, secretName)
+}
+
+// GetStringSecretWithContext is the same as GetStringSecret, but uses the
+// supplied context for the underlying AWS request. A cancelled or expired
+// context aborts the request.
+//
+func GetStringSecretWithContext(ctx context.Context, sess *session.Session, secretName string) (secret string, err error) {
+	return GetStringSecretVersionWithContext(ctx, sess, secretName, "", "")
+}
 
-	//Create a Secrets Manager client if one wasn't passed in
-	if sess == nil {
-		sess, err = session.NewSessionWithOptions(
-			session.Options{
-				SharedConfigState: session.SharedConfigEnable,
-			},
-		)
-		if err != nil {
-			return "", err
-		}
+// StrictGetStringSecret is the same as GetStringSecret, but returns an error
+// matching ErrSecretEmpty if the secret's value is empty or only whitespace,
+// which almost always indicates a misconfigured secret.
+//
+func StrictGetStringSecret(sess *session.Session, secretName string) (secret string, err error) {
+	return StrictGetStringSecretWithContext(context.Background(), sess, secretName)
+}
+
+// StrictGetStringSecretWithContext is the same as StrictGetStringSecret, but
+// uses the supplied context for the underlying AWS request.
+//
+func StrictGetStringSecretWithContext(ctx context.Context, sess *session.Session, secretName string) (secret string, err error) {
+	secret, err = GetStringSecretWithContext(ctx, sess, secretName)
+	if err != nil {
+		return "", err
 	}
 
-	svc := secretsmanager.New(sess)
-	input := &secretsmanager.GetSecretValueInput{
-		SecretId:     aws.String(secretName),
-		VersionStage: aws.String("AWSCURRENT"), // VersionStage defaults to AWSCURRENT if unspecified
+	if strings.TrimSpace(secret) == "" {
+		return "", errors.Wrapf(ErrSecretEmpty, "Secret %q", secretName)
+	}
+
+	return secret, nil
+}
+
+// GetTrimmedStringSecret is the same as GetStringSecret, but removes any
+// leading and trailing whitespace, such as the newline left behind when a
+// secret is stored from a file.
+//
+func GetTrimmedStringSecret(sess *session.Session, secretName string) (secret string, err error) {
+	return GetTrimmedStringSecretWithContext(context.Background(), sess, secretName)
+}
+
+// GetTrimmedStringSecretWithContext is the same as GetTrimmedStringSecret,
+// but uses the supplied context for the underlying AWS request.
+//
+func GetTrimmedStringSecretWithContext(ctx context.Context, sess *session.Session, secretName string) (secret string, err error) {
+	secret, err = GetStringSecretWithContext(ctx, sess, secretName)
+	if err != nil {
+		return "", err
 	}
+	return strings.TrimSpace(secret), nil
+}
+
+// GetStringSecretVersion retrieves a specific version of the named secret
+// from AWS Secrets Manager and returns it in its raw form. The version can be
+// selected by VersionId, by staging label (e.g. AWSPREVIOUS) or both. When both
+// are supplied, AWS requires that they refer to the same version and rejects
+// the request otherwise. When both are empty, AWSCURRENT is retrieved.
+//
+func GetStringSecretVersion(sess *session.Session, secretName, versionID, versionStage string) (secret string, err error) {
+	return GetStringSecretVersionWithContext(context.Background(), sess, secretName, versionID, versionStage)
+}
 
-	// In this sample we only handle the specific exceptions for the 'GetSecretValue' API.
-	// See https://docs.aws.amazon.com/secretsmanager/latest/apireference/API_GetSecretValue.html
+// GetStringSecretVersionWithContext is the same as GetStringSecretVersion,
+// but uses the supplied context for the underlying AWS request.
+//
+func GetStringSecretVersionWithContext(ctx context.Context, sess *session.Session, secretName, versionID, versionStage string) (secret string, err error) {
+	c := clientFor(sess)
+	if c.err != nil {
+		return "", c.err
+	}
+	return getStringSecret(ctx, c.api, newGetSecretValueInput(secretName, versionID, versionStage))
+}
 
-	result, err := svc.GetSecretValue(input)
+// getStringSecret performs the supplied GetSecretValue request and returns
+// the SecretString from the result, or an error matching ErrSecretIsBinary
+// or ErrSecretEmpty if there isn't one.
+//
+func getStringSecret(ctx context.Context, client SecretsManagerAPI, input *secretsmanager.GetSecretValueInput) (secret string, err error) {
+	result, err := getSecretValue(ctx, client, input)
 	if err != nil {
-		if aerr, ok := err.(awserr.Error); ok {
-			return "", errors.Wrapf(err, "Failed to get secret from AWS Secrets Manager: %s", aerr.Code())
-		}
-		return "", errors.Wrapf(err, "Fasiled to get secret from AWS Secrets Manager: Unknown error description")
+		return "", err
 	}
 
 	if result.SecretString != nil {
@@ -175,5 +320,38 @@ func GetStringSecret(sess *session.Session, secretName string) (secret string, e
 		return secret, nil
 	}
 
-	return "", errors.New("Secret is not a string")
+	return "", noStringError(aws.StringValue(input.SecretId), result.SecretBinary)
+}
+
+// noStringError explains why a secret has no SecretString: either it holds
+// a binary value instead, or it holds nothing at all.
+//
+func noStringError(secretName string, binary []byte) error {
+	if binary != nil {
+		return errors.Wrapf(ErrSecretIsBinary, "Secret %q", secretName)
+	}
+	return errors.Wrapf(ErrSecretEmpty, "Secret %q", secretName)
+}
+
+// DefaultVersionStage is the staging label retrieved when neither a VersionId
+// nor a VersionStage is requested.
+const DefaultVersionStage = "AWSCURRENT"
+
+// newGetSecretValueInput builds the GetSecretValue request for the named
+// secret, defaulting to the AWSCURRENT stage when no version is specified.
+//
+func newGetSecretValueInput(secretName, versionID, versionStage string) *secretsmanager.GetSecretValueInput {
+	input := &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(secretName),
+	}
+	if versionID != "" {
+		input.VersionId = aws.String(versionID)
+	}
+	if versionStage != "" {
+		input.VersionStage = aws.String(versionStage)
+	}
+	if versionID == "" && versionStage == "" {
+		input.VersionStage = aws.String(DefaultVersionStage)
+	}
+	return input
 }
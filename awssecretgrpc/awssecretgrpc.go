@@ -0,0 +1,82 @@
+// Package awssecretgrpc maps errors returned by package awssecret to gRPC
+// statuses, so that a handler which fails to read a secret can return a
+// meaningful code rather than Unknown:
+//
+//	key, err := awssecret.GetStringSecret(sess, "prod/api-key")
+//	if err != nil {
+//		return nil, awssecretgrpc.ToGRPCStatus(err).Err()
+//	}
+//
+package awssecretgrpc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/adlio/awssecret"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ToGRPCStatus converts an error returned by package awssecret into a gRPC
+// status: NotFound for missing secrets, ResourceExhausted for throttling,
+// Unavailable for AWS server errors, InvalidArgument for invalid requests
+// and malformed secret ARNs, Canceled or DeadlineExceeded when the
+// request's context ended, and Unknown for anything else. A nil error
+// converts to an OK status.
+//
+func ToGRPCStatus(err error) *status.Status {
+	return status.New(Code(err), messageOf(err))
+}
+
+// Code returns the gRPC code ToGRPCStatus would use for err.
+//
+func Code(err error) codes.Code {
+	switch {
+	case err == nil:
+		return codes.OK
+	case errors.Is(err, context.Canceled):
+		return codes.Canceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return codes.DeadlineExceeded
+	case errors.Is(err, awssecret.ErrSecretNotFound):
+		return codes.NotFound
+	case errors.Is(err, awssecret.ErrInvalidRequest), errors.Is(err, awssecret.ErrMalformedARN):
+		return codes.InvalidArgument
+	}
+
+	// The SDK reports an ended context as a RequestCanceled awserr.Error,
+	// which doesn't unwrap to the context's error but carries it as OrigErr.
+	var aerr awserr.Error
+	if errors.As(err, &aerr) && aerr.Code() == request.CanceledErrorCode {
+		if errors.Is(aerr.OrigErr(), context.DeadlineExceeded) {
+			return codes.DeadlineExceeded
+		}
+		return codes.Canceled
+	}
+
+	var serr *awssecret.SecretError
+	if !errors.As(err, &serr) {
+		return codes.Unknown
+	}
+
+	switch {
+	case request.IsErrorThrottle(awserr.New(serr.Code, serr.Message, nil)):
+		return codes.ResourceExhausted
+	case serr.StatusCode >= 500, serr.Code == secretsmanager.ErrCodeInternalServiceError:
+		return codes.Unavailable
+	case serr.Code == secretsmanager.ErrCodeInvalidParameterException:
+		return codes.InvalidArgument
+	}
+	return codes.Unknown
+}
+
+func messageOf(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
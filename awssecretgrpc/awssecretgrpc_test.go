@@ -0,0 +1,94 @@
+package awssecretgrpc
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/adlio/awssecret"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+)
+
+// fetchError returns err as package awssecret returns AWS errors: wrapped
+// in a *SecretError and an explanatory message.
+func fetchError(t *testing.T, err error) error {
+	t.Helper()
+	client := awssecret.NewClientWithAPI(stub{err: err})
+	_, got := client.GetString("db")
+	if got == nil {
+		t.Fatal("expected an error")
+	}
+	return got
+}
+
+type stub struct{ err error }
+
+func (s stub) GetSecretValue(*secretsmanager.GetSecretValueInput) (*secretsmanager.GetSecretValueOutput, error) {
+	return nil, s.err
+}
+
+func TestCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want codes.Code
+	}{
+		{"not found", awserr.New(secretsmanager.ErrCodeResourceNotFoundException, "", nil), codes.NotFound},
+		{"invalid request", awserr.New(secretsmanager.ErrCodeInvalidRequestException, "", nil), codes.InvalidArgument},
+		{"invalid parameter", awserr.New(secretsmanager.ErrCodeInvalidParameterException, "", nil), codes.InvalidArgument},
+		{"throttled", awserr.New("ThrottlingException", "", nil), codes.ResourceExhausted},
+		{"internal error", awserr.New(secretsmanager.ErrCodeInternalServiceError, "", nil), codes.Unavailable},
+		{"server error", awserr.NewRequestFailure(awserr.New("Boom", "", nil), 503, "req-1"), codes.Unavailable},
+		{"deadline", awserr.New(request.CanceledErrorCode, "request context canceled", context.DeadlineExceeded), codes.DeadlineExceeded},
+		{"canceled", awserr.New(request.CanceledErrorCode, "request context canceled", context.Canceled), codes.Canceled},
+		{"decryption failure", awserr.New(secretsmanager.ErrCodeDecryptionFailure, "", nil), codes.Unknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Code(fetchError(t, tt.err)); got != tt.want {
+				t.Errorf("Code() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCodeWithoutAWSError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want codes.Code
+	}{
+		{nil, codes.OK},
+		{context.Canceled, codes.Canceled},
+		{errors.Wrap(context.DeadlineExceeded, "Failed"), codes.DeadlineExceeded},
+		{errors.Wrap(awssecret.ErrSecretNotFound, "No secret"), codes.NotFound},
+		{errors.Wrap(awssecret.ErrMalformedARN, "Invalid secret ID"), codes.InvalidArgument},
+		{fmt.Errorf("something else"), codes.Unknown},
+	}
+	for _, tt := range tests {
+		if got := Code(tt.err); got != tt.want {
+			t.Errorf("Code(%v) = %s, want %s", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestCodeMalformedARN(t *testing.T) {
+	_, err := awssecret.NewClientWithAPI(stub{}).GetString("arn:aws:secretsmanager:us-east-1")
+	if got := Code(err); got != codes.InvalidArgument {
+		t.Errorf("Code(%v) = %s, want InvalidArgument", err, got)
+	}
+}
+
+func TestToGRPCStatus(t *testing.T) {
+	err := fetchError(t, awserr.New(secretsmanager.ErrCodeResourceNotFoundException, "gone", nil))
+	st := ToGRPCStatus(err)
+	if st.Code() != codes.NotFound || st.Message() != err.Error() {
+		t.Errorf("status = %v", st)
+	}
+	if ToGRPCStatus(nil).Code() != codes.OK {
+		t.Error("nil error should convert to OK")
+	}
+}
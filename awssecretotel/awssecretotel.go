@@ -0,0 +1,114 @@
+// Package awssecretotel traces secrets retrieved by package awssecret with
+// OpenTelemetry. Wrap the Secrets Manager client in a TracingClient to
+// record a span around each GetSecretValue request:
+//
+//	client := awssecretotel.NewTracingClient(secretsmanager.New(sess), awssecretotel.Options{
+//		Tracer: otel.Tracer("myapp"),
+//	})
+//	key, err := awssecret.GetStringSecretWithClient(client, "prod/api-key")
+//
+package awssecretotel
+
+import (
+	"context"
+
+	"github.com/adlio/awssecret"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SpanName names the span created around each GetSecretValue request.
+const SpanName = "awssecret.GetSecretValue"
+
+// Attributes recorded on each span. The secret's value is never recorded.
+const (
+	AttrSecretName = attribute.Key("awssecret.secret_name")
+	AttrStatus     = attribute.Key("awssecret.status")
+)
+
+// StatusOK is recorded as the status attribute of successful requests.
+// Failed requests record the AWS error code, or "error" if there isn't one.
+const StatusOK = "ok"
+
+// Options configures a TracingClient.
+//
+type Options struct {
+	// Tracer creates the spans. If it is nil, the TracingClient passes
+	// requests through without tracing them.
+	Tracer trace.Tracer
+}
+
+// TracingClient wraps an awssecret.SecretsManagerAPI and records a span
+// named "awssecret.GetSecretValue" around each request, as a child of any
+// span in the request's context. It can be passed to any of the
+// awssecret WithClient functions, or to awssecret.NewClientWithAPI.
+//
+type TracingClient struct {
+	client awssecret.SecretsManagerAPI
+	tracer trace.Tracer
+}
+
+// NewTracingClient returns a TracingClient which traces requests made
+// through client using opts.Tracer.
+//
+func NewTracingClient(client awssecret.SecretsManagerAPI, opts Options) *TracingClient {
+	return &TracingClient{client: client, tracer: opts.Tracer}
+}
+
+// GetSecretValue implements awssecret.SecretsManagerAPI.
+//
+func (c *TracingClient) GetSecretValue(input *secretsmanager.GetSecretValueInput) (*secretsmanager.GetSecretValueOutput, error) {
+	return c.GetSecretValueWithContext(context.Background(), input)
+}
+
+// GetSecretValueWithContext is the same as GetSecretValue, but starts the
+// span from the supplied context and passes the span's context on to the
+// wrapped client.
+//
+func (c *TracingClient) GetSecretValueWithContext(ctx context.Context, input *secretsmanager.GetSecretValueInput, opts ...request.Option) (*secretsmanager.GetSecretValueOutput, error) {
+	if c.tracer == nil {
+		return c.call(ctx, input, opts...)
+	}
+
+	ctx, span := c.tracer.Start(ctx, SpanName,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(AttrSecretName.String(aws.StringValue(input.SecretId))),
+	)
+	defer span.End()
+
+	output, err := c.call(ctx, input, opts...)
+	if err != nil {
+		status := "error"
+		if aerr, ok := err.(awserr.Error); ok {
+			status = aerr.Code()
+		}
+		span.SetAttributes(AttrStatus.String(status))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, status)
+		return nil, err
+	}
+
+	span.SetAttributes(AttrStatus.String(StatusOK))
+	return output, nil
+}
+
+// call uses the wrapped client's GetSecretValueWithContext when it has
+// one, so that cancellation reaches the AWS request.
+//
+func (c *TracingClient) call(ctx context.Context, input *secretsmanager.GetSecretValueInput, opts ...request.Option) (*secretsmanager.GetSecretValueOutput, error) {
+	type contextClient interface {
+		GetSecretValueWithContext(context.Context, *secretsmanager.GetSecretValueInput, ...request.Option) (*secretsmanager.GetSecretValueOutput, error)
+	}
+	if cc, ok := c.client.(contextClient); ok {
+		return cc.GetSecretValueWithContext(ctx, input, opts...)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.client.GetSecretValue(input)
+}
@@ -0,0 +1,149 @@
+package awssecretotel
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/adlio/awssecret"
+	"github.com/adlio/awssecret/awssecrettest"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// newRecordedTracer returns a Tracer whose ended spans are kept by the
+// returned SpanRecorder.
+func newRecordedTracer(t *testing.T) (trace.Tracer, *tracetest.SpanRecorder) {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	t.Cleanup(func() { provider.Shutdown(context.Background()) })
+	return provider.Tracer("awssecretotel_test"), recorder
+}
+
+func attr(span sdktrace.ReadOnlySpan, key attribute.Key) string {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value.AsString()
+		}
+	}
+	return ""
+}
+
+func TestTracingClient(t *testing.T) {
+	fake := awssecrettest.NewFakeClient()
+	fake.SetString("db", "hunter2")
+	fake.SetError("throttled", awserr.New("ThrottlingException", "rate exceeded", nil))
+	fake.SetError("broken", errors.New("connection reset"))
+
+	tests := []struct {
+		secret     string
+		wantStatus string
+		wantErr    bool
+	}{
+		{"db", StatusOK, false},
+		{"missing", secretsmanager.ErrCodeResourceNotFoundException, true},
+		{"throttled", "ThrottlingException", true},
+		{"broken", "error", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.secret, func(t *testing.T) {
+			tracer, recorder := newRecordedTracer(t)
+			client := NewTracingClient(fake, Options{Tracer: tracer})
+
+			got, err := awssecret.GetStringSecretWithClient(client, tt.secret)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != "hunter2" {
+				t.Errorf("got %q, want hunter2", got)
+			}
+
+			spans := recorder.Ended()
+			if len(spans) != 1 {
+				t.Fatalf("got %d spans, want 1", len(spans))
+			}
+			span := spans[0]
+			if span.Name() != SpanName || span.SpanKind() != trace.SpanKindClient {
+				t.Errorf("span = %q (%v), want %q (client)", span.Name(), span.SpanKind(), SpanName)
+			}
+			if got := attr(span, AttrSecretName); got != tt.secret {
+				t.Errorf("secret name = %q, want %q", got, tt.secret)
+			}
+			if got := attr(span, AttrStatus); got != tt.wantStatus {
+				t.Errorf("status = %q, want %q", got, tt.wantStatus)
+			}
+			wantCode := codes.Unset
+			if tt.wantErr {
+				wantCode = codes.Error
+			}
+			if span.Status().Code != wantCode {
+				t.Errorf("span status = %v, want %v", span.Status().Code, wantCode)
+			}
+			if tt.wantErr && len(span.Events()) == 0 {
+				t.Error("expected the error to be recorded as a span event")
+			}
+			for _, kv := range span.Attributes() {
+				if kv.Value.AsString() == "hunter2" {
+					t.Errorf("attribute %s records the secret's value", kv.Key)
+				}
+			}
+		})
+	}
+}
+
+func TestTracingClientParentSpan(t *testing.T) {
+	fake := awssecrettest.NewFakeClient()
+	fake.SetString("db", "hunter2")
+	tracer, recorder := newRecordedTracer(t)
+	client := NewTracingClient(fake, Options{Tracer: tracer})
+
+	ctx, parent := tracer.Start(context.Background(), "handler")
+	if _, err := awssecret.GetStringSecretWithClientContext(ctx, client, "db"); err != nil {
+		t.Fatal(err)
+	}
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+	if got, want := spans[0].Parent().SpanID(), parent.SpanContext().SpanID(); got != want {
+		t.Errorf("parent = %v, want %v", got, want)
+	}
+}
+
+func TestTracingClientWithoutTracer(t *testing.T) {
+	fake := awssecrettest.NewFakeClient()
+	fake.SetString("db", "hunter2")
+	client := NewTracingClient(fake, Options{})
+
+	got, err := awssecret.GetStringSecretWithClient(client, "db")
+	if err != nil || got != "hunter2" {
+		t.Fatalf("got %q, %v", got, err)
+	}
+	if n := fake.Calls("db"); n != 1 {
+		t.Errorf("calls = %d, want 1", n)
+	}
+}
+
+func TestTracingClientCanceled(t *testing.T) {
+	fake := awssecrettest.NewFakeClient()
+	fake.SetString("db", "hunter2")
+	tracer, recorder := newRecordedTracer(t)
+	client := NewTracingClient(fake, Options{Tracer: tracer})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := awssecret.GetStringSecretWithClientContext(ctx, client, "db"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if spans := recorder.Ended(); len(spans) != 1 || spans[0].Status().Code != codes.Error {
+		t.Errorf("spans = %v, want one failed span", spans)
+	}
+}
@@ -0,0 +1,91 @@
+// Package awssecretprom exports Prometheus metrics for secrets retrieved by
+// package awssecret: a counter of retrievals by secret and result, and a
+// histogram of their latency. Register a Collector and install it as the
+// awssecret Logger:
+//
+//	collector := awssecretprom.NewCollector()
+//	prometheus.MustRegister(collector)
+//	awssecret.SetLogger(collector)
+//
+package awssecretprom
+
+import (
+	"github.com/adlio/awssecret"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Results recorded in the result label of the fetches counter.
+const (
+	ResultSuccess  = "success"
+	ResultError    = "error"
+	ResultCacheHit = "cache_hit"
+)
+
+// Collector is a prometheus.Collector which counts secret retrievals by
+// result and observes their latency. It implements awssecret.Logger, so it
+// is activated by passing it to awssecret.SetLogger.
+//
+type Collector struct {
+	label   func(secretName string) string
+	fetches *prometheus.CounterVec
+	latency *prometheus.HistogramVec
+}
+
+// NewCollector returns a Collector whose metrics are labeled with the name
+// of each secret. Use NewCollectorWithLabel to label them differently.
+//
+func NewCollector() *Collector {
+	return NewCollectorWithLabel(func(secretName string) string { return secretName })
+}
+
+// NewCollectorWithLabel returns a Collector whose secret label is the
+// result of calling label with each secret's name, e.g. to map names to an
+// operator-chosen identifier or to limit cardinality.
+//
+func NewCollectorWithLabel(label func(secretName string) string) *Collector {
+	return &Collector{
+		label: label,
+		fetches: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "awssecret",
+			Name:      "fetches_total",
+			Help:      "Number of secret retrievals, by secret and result.",
+		}, []string{"secret", "result"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "awssecret",
+			Name:      "fetch_duration_seconds",
+			Help:      "Latency of secret retrievals, by secret.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"secret"}),
+	}
+}
+
+// LogFetch implements awssecret.Logger.
+//
+func (c *Collector) LogFetch(e awssecret.FetchEvent) {
+	secret := c.label(e.SecretName)
+
+	result := ResultSuccess
+	switch {
+	case e.Err != nil:
+		result = ResultError
+	case e.CacheHit:
+		result = ResultCacheHit
+	}
+
+	c.fetches.WithLabelValues(secret, result).Inc()
+	c.latency.WithLabelValues(secret).Observe(e.Duration.Seconds())
+}
+
+// Describe implements prometheus.Collector.
+//
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.fetches.Describe(ch)
+	c.latency.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+//
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.fetches.Collect(ch)
+	c.latency.Collect(ch)
+}
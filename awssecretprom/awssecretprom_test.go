@@ -0,0 +1,52 @@
+package awssecretprom
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adlio/awssecret"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCollectorLogFetch(t *testing.T) {
+	tests := []struct {
+		name       string
+		event      awssecret.FetchEvent
+		wantResult string
+	}{
+		{"success", awssecret.FetchEvent{SecretName: "db", Duration: time.Millisecond}, ResultSuccess},
+		{"error", awssecret.FetchEvent{SecretName: "db", Err: errors.New("boom")}, ResultError},
+		{"cache hit", awssecret.FetchEvent{SecretName: "db", CacheHit: true}, ResultCacheHit},
+		{"stale cache hit", awssecret.FetchEvent{SecretName: "db", CacheHit: true, Stale: true}, ResultCacheHit},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCollector()
+			c.LogFetch(tt.event)
+
+			if got := testutil.ToFloat64(c.fetches.WithLabelValues("db", tt.wantResult)); got != 1 {
+				t.Errorf("fetches{result=%q} = %v, want 1", tt.wantResult, got)
+			}
+			if got := testutil.CollectAndCount(c, "awssecret_fetches_total"); got != 1 {
+				t.Errorf("fetches series = %d, want 1", got)
+			}
+			if got := testutil.CollectAndCount(c, "awssecret_fetch_duration_seconds"); got != 1 {
+				t.Errorf("latency series = %d, want 1", got)
+			}
+		})
+	}
+}
+
+func TestNewCollectorWithLabel(t *testing.T) {
+	c := NewCollectorWithLabel(func(name string) string {
+		return strings.SplitN(name, "/", 2)[0]
+	})
+	c.LogFetch(awssecret.FetchEvent{SecretName: "prod/db"})
+	c.LogFetch(awssecret.FetchEvent{SecretName: "prod/api"})
+
+	if got := testutil.ToFloat64(c.fetches.WithLabelValues("prod", ResultSuccess)); got != 2 {
+		t.Errorf("fetches{secret=prod} = %v, want 2", got)
+	}
+}
@@ -0,0 +1,220 @@
+// Package awssecrettest provides an in-memory fake of AWS Secrets Manager
+// for testing code which uses package awssecret.
+//
+//	client := awssecrettest.NewFakeClient()
+//	client.SetString("prod/api-key", "hunter2")
+//	client.SetStringVersion("prod/api-key", "AWSPENDING", "correct-horse")
+//	client.SetError("prod/broken", awserr.New(secretsmanager.ErrCodeDecryptionFailure, "denied", nil))
+//	key, err := awssecret.GetStringSecretWithClient(client, "prod/api-key")
+//
+package awssecrettest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+)
+
+// currentStage and previousStage are the staging labels AWS manages itself.
+const (
+	currentStage  = "AWSCURRENT"
+	previousStage = "AWSPREVIOUS"
+)
+
+// FakeClient is an in-memory implementation of awssecret.SecretsManagerAPI.
+// Each secret holds a list of versions, selected by VersionId or staging
+// label as in AWS. Secrets, versions and labels which haven't been seeded
+// fail with a ResourceNotFoundException, just as they would in AWS. It is
+// safe for concurrent use.
+//
+type FakeClient struct {
+	mu      sync.RWMutex
+	secrets map[string][]*secretsmanager.GetSecretValueOutput
+	errs    map[string]error
+	calls   map[string]int
+}
+
+// NewFakeClient returns a FakeClient with no secrets.
+//
+func NewFakeClient() *FakeClient {
+	return &FakeClient{
+		secrets: make(map[string][]*secretsmanager.GetSecretValueOutput),
+		errs:    make(map[string]error),
+		calls:   make(map[string]int),
+	}
+}
+
+// SetString seeds name with a new AWSCURRENT version holding a
+// SecretString value. The version which was AWSCURRENT becomes AWSPREVIOUS,
+// keeping any other labels it had.
+//
+func (f *FakeClient) SetString(name, value string) {
+	f.SetStringVersion(name, currentStage, value)
+}
+
+// SetStringVersion seeds name with a new version holding a SecretString
+// value, labeled with stage. The label is moved from any older version
+// which had it. It returns the new version's VersionId.
+//
+func (f *FakeClient) SetStringVersion(name, stage, value string) (versionID string) {
+	return f.set(name, stage, &secretsmanager.GetSecretValueOutput{SecretString: aws.String(value)})
+}
+
+// SetBinary seeds name with a new AWSCURRENT version holding a
+// SecretBinary value.
+//
+func (f *FakeClient) SetBinary(name string, value []byte) {
+	f.set(name, currentStage, &secretsmanager.GetSecretValueOutput{SecretBinary: value})
+}
+
+// SetJSON seeds name with the JSON encoding of v as its SecretString.
+//
+func (f *FakeClient) SetJSON(name string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	f.SetString(name, string(data))
+	return nil
+}
+
+// SetError makes every request for name fail with err, until Delete is
+// called. Use awserr.New with a secretsmanager.ErrCode constant to simulate
+// a specific AWS error.
+//
+func (f *FakeClient) SetError(name string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.errs[name] = err
+}
+
+// Delete removes every version and any error seeded for name.
+//
+func (f *FakeClient) Delete(name string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.secrets, name)
+	delete(f.errs, name)
+}
+
+// Calls returns the number of GetSecretValue requests made for name.
+//
+func (f *FakeClient) Calls(name string) int {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.calls[name]
+}
+
+func (f *FakeClient) set(name, stage string, output *secretsmanager.GetSecretValueOutput) (versionID string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	versions := f.secrets[name]
+	versionID = fmt.Sprintf("%032d", len(versions)+1)
+	output.Name = aws.String(name)
+	output.ARN = aws.String(fmt.Sprintf("arn:aws:secretsmanager:us-east-1:123456789012:secret:%s", name))
+	output.VersionId = aws.String(versionID)
+	output.VersionStages = aws.StringSlice([]string{stage})
+
+	if stage == currentStage {
+		if current := findVersion(versions, "", currentStage); current != nil {
+			removeStage(versions, previousStage)
+			removeStage(versions, currentStage)
+			current.VersionStages = append(current.VersionStages, aws.String(previousStage))
+		}
+	} else {
+		removeStage(versions, stage)
+	}
+
+	f.secrets[name] = append(versions, output)
+	delete(f.errs, name)
+	return versionID
+}
+
+// findVersion returns the version matching versionID and stage, either of
+// which may be empty, or nil if there isn't one.
+//
+func findVersion(versions []*secretsmanager.GetSecretValueOutput, versionID, stage string) *secretsmanager.GetSecretValueOutput {
+	for _, v := range versions {
+		if versionID != "" && aws.StringValue(v.VersionId) != versionID {
+			continue
+		}
+		if stage != "" && !hasStage(v, stage) {
+			continue
+		}
+		return v
+	}
+	return nil
+}
+
+func hasStage(v *secretsmanager.GetSecretValueOutput, stage string) bool {
+	for _, s := range v.VersionStages {
+		if aws.StringValue(s) == stage {
+			return true
+		}
+	}
+	return false
+}
+
+// removeStage takes the stage label off every version which has it.
+//
+func removeStage(versions []*secretsmanager.GetSecretValueOutput, stage string) {
+	for _, v := range versions {
+		var kept []*string
+		for _, s := range v.VersionStages {
+			if aws.StringValue(s) != stage {
+				kept = append(kept, s)
+			}
+		}
+		v.VersionStages = kept
+	}
+}
+
+// GetSecretValue implements awssecret.SecretsManagerAPI.
+//
+func (f *FakeClient) GetSecretValue(input *secretsmanager.GetSecretValueInput) (*secretsmanager.GetSecretValueOutput, error) {
+	return f.GetSecretValueWithContext(context.Background(), input)
+}
+
+// GetSecretValueWithContext is the same as GetSecretValue, but fails if the
+// context is already done. The version is selected by the input's VersionId
+// and VersionStage, defaulting to AWSCURRENT when neither is set.
+//
+func (f *FakeClient) GetSecretValueWithContext(ctx context.Context, input *secretsmanager.GetSecretValueInput, _ ...request.Option) (*secretsmanager.GetSecretValueOutput, error) {
+	name := aws.StringValue(input.SecretId)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls[name]++
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err, ok := f.errs[name]; ok {
+		return nil, err
+	}
+	versions, ok := f.secrets[name]
+	if !ok {
+		return nil, awserr.New(secretsmanager.ErrCodeResourceNotFoundException, "Secrets Manager can't find the specified secret.", nil)
+	}
+
+	versionID := aws.StringValue(input.VersionId)
+	stage := aws.StringValue(input.VersionStage)
+	if versionID == "" && stage == "" {
+		stage = currentStage
+	}
+	output := findVersion(versions, versionID, stage)
+	if output == nil {
+		return nil, awserr.New(secretsmanager.ErrCodeResourceNotFoundException, "Secrets Manager can't find the specified secret value.", nil)
+	}
+
+	copied := *output
+	copied.VersionStages = append([]*string(nil), output.VersionStages...)
+	return &copied, nil
+}
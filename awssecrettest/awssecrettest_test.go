@@ -0,0 +1,173 @@
+package awssecrettest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/adlio/awssecret"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+)
+
+func TestFakeClientVersions(t *testing.T) {
+	client := NewFakeClient()
+	client.SetString("db", "old")
+	client.SetString("db", "current")
+	pendingID := client.SetStringVersion("db", "AWSPENDING", "pending")
+
+	tests := []struct {
+		name      string
+		versionID string
+		stage     string
+		want      string
+		notFound  bool
+	}{
+		{name: "defaults to AWSCURRENT", want: "current"},
+		{name: "AWSCURRENT", stage: "AWSCURRENT", want: "current"},
+		{name: "AWSPREVIOUS", stage: "AWSPREVIOUS", want: "old"},
+		{name: "custom stage", stage: "AWSPENDING", want: "pending"},
+		{name: "by version ID", versionID: pendingID, want: "pending"},
+		{name: "version ID and matching stage", versionID: pendingID, stage: "AWSPENDING", want: "pending"},
+		{name: "version ID and other stage", versionID: pendingID, stage: "AWSCURRENT", notFound: true},
+		{name: "unknown stage", stage: "AWSNOPE", notFound: true},
+		{name: "unknown version ID", versionID: "nope", notFound: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := &secretsmanager.GetSecretValueInput{SecretId: aws.String("db")}
+			if tt.versionID != "" {
+				input.VersionId = aws.String(tt.versionID)
+			}
+			if tt.stage != "" {
+				input.VersionStage = aws.String(tt.stage)
+			}
+
+			output, err := client.GetSecretValue(input)
+			if tt.notFound {
+				assertCode(t, err, secretsmanager.ErrCodeResourceNotFoundException)
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := aws.StringValue(output.SecretString); got != tt.want {
+				t.Errorf("SecretString = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFakeClientSetStringKeepsCustomLabels(t *testing.T) {
+	client := NewFakeClient()
+	client.SetString("db", "old")
+	old := client.secrets["db"][0]
+	old.VersionStages = append(old.VersionStages, aws.String("blue"))
+	client.SetString("db", "current")
+
+	if got := aws.StringValueSlice(old.VersionStages); !reflect.DeepEqual(got, []string{"blue", "AWSPREVIOUS"}) {
+		t.Errorf("old version's stages = %v, want [blue AWSPREVIOUS]", got)
+	}
+	output, err := client.GetSecretValue(&secretsmanager.GetSecretValueInput{
+		SecretId:     aws.String("db"),
+		VersionStage: aws.String("blue"),
+	})
+	if err != nil || aws.StringValue(output.SecretString) != "old" {
+		t.Errorf("blue version = %v, %v; want old", output, err)
+	}
+}
+
+func TestFakeClientSeeding(t *testing.T) {
+	client := NewFakeClient()
+	client.SetString("str", "hunter2")
+	if err := client.SetJSON("json", map[string]int{"port": 5432}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		secret string
+		want   string
+	}{
+		{"string", "str", "hunter2"},
+		{"json", "json", `{"port":5432}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := awssecret.GetStringSecretWithClient(client, tt.secret)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if n := client.Calls(tt.secret); n != 1 {
+				t.Errorf("Calls = %d, want 1", n)
+			}
+		})
+	}
+
+	client.SetBinary("bin", []byte{1, 2})
+	output, err := client.GetSecretValue(&secretsmanager.GetSecretValueInput{SecretId: aws.String("bin")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(output.SecretBinary) != "\x01\x02" || output.SecretString != nil {
+		t.Errorf("binary secret = %v", output)
+	}
+
+	output, err = client.GetSecretValue(&secretsmanager.GetSecretValueInput{SecretId: aws.String("str")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if aws.StringValue(output.Name) != "str" || aws.StringValue(output.ARN) == "" || aws.StringValue(output.VersionId) == "" {
+		t.Errorf("metadata wasn't filled in: %v", output)
+	}
+	if stages := aws.StringValueSlice(output.VersionStages); len(stages) != 1 || stages[0] != "AWSCURRENT" {
+		t.Errorf("VersionStages = %v, want [AWSCURRENT]", stages)
+	}
+}
+
+func TestFakeClientErrors(t *testing.T) {
+	injected := awserr.New(secretsmanager.ErrCodeDecryptionFailure, "denied", nil)
+
+	client := NewFakeClient()
+	client.SetString("db", "hunter2")
+	client.SetError("db", injected)
+
+	if _, err := client.GetSecretValue(&secretsmanager.GetSecretValueInput{SecretId: aws.String("db")}); err != injected {
+		t.Errorf("err = %v, want the injected error", err)
+	}
+
+	client.SetString("db", "rotated")
+	if _, err := client.GetSecretValue(&secretsmanager.GetSecretValueInput{SecretId: aws.String("db")}); err != nil {
+		t.Errorf("seeding a value should clear the error, got %v", err)
+	}
+
+	client.Delete("db")
+	_, err := client.GetSecretValue(&secretsmanager.GetSecretValueInput{SecretId: aws.String("db")})
+	assertCode(t, err, secretsmanager.ErrCodeResourceNotFoundException)
+
+	_, err = client.GetSecretValue(&secretsmanager.GetSecretValueInput{SecretId: aws.String("missing")})
+	assertCode(t, err, secretsmanager.ErrCodeResourceNotFoundException)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	client.SetString("db", "hunter2")
+	if _, err := client.GetSecretValueWithContext(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String("db")}); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+	if n := client.Calls("db"); n != 4 {
+		t.Errorf("Calls = %d, want 4", n)
+	}
+}
+
+func assertCode(t *testing.T, err error, code string) {
+	t.Helper()
+	var aerr awserr.Error
+	if !errors.As(err, &aerr) || aerr.Code() != code {
+		t.Errorf("err = %v, want %s", err, code)
+	}
+}
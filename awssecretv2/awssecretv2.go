@@ -0,0 +1,143 @@
+// Package awssecretv2 reads secrets from AWS Secrets Manager using
+// aws-sdk-go-v2. It adapts a v2 client to awssecret.SecretsManagerAPI, so
+// everything package awssecret offers (JSON decoding, DSN builders,
+// caching, retries and error sentinels) works the same way with an
+// aws.Config as it does with a v1 session.
+//
+package awssecretv2
+
+import (
+	"context"
+	"errors"
+
+	"github.com/adlio/awssecret"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	smv1 "github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/smithy-go"
+)
+
+// GetSecretValueAPI is the subset of the v2 Secrets Manager client used by
+// this package. *secretsmanager.Client satisfies it, and tests can supply a
+// stub in its place.
+//
+type GetSecretValueAPI interface {
+	GetSecretValue(context.Context, *secretsmanager.GetSecretValueInput, ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error)
+}
+
+// API adapts a v2 client to awssecret.SecretsManagerAPI.
+//
+type API struct {
+	client GetSecretValueAPI
+}
+
+// NewAPI returns an API which makes requests using client.
+//
+func NewAPI(client GetSecretValueAPI) *API {
+	return &API{client: client}
+}
+
+// NewClient returns an awssecret.Client which reads secrets using a v2
+// client built from cfg.
+//
+func NewClient(cfg aws.Config) *awssecret.Client {
+	return awssecret.NewClientWithAPI(NewAPI(secretsmanager.NewFromConfig(cfg)))
+}
+
+// GetSecretValue implements awssecret.SecretsManagerAPI.
+//
+func (a *API) GetSecretValue(input *smv1.GetSecretValueInput) (*smv1.GetSecretValueOutput, error) {
+	return a.GetSecretValueWithContext(context.Background(), input)
+}
+
+// GetSecretValueWithContext is the same as GetSecretValue, but uses the
+// supplied context for the underlying request.
+//
+func (a *API) GetSecretValueWithContext(ctx context.Context, input *smv1.GetSecretValueInput, _ ...request.Option) (*smv1.GetSecretValueOutput, error) {
+	result, err := a.client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId:     input.SecretId,
+		VersionId:    input.VersionId,
+		VersionStage: input.VersionStage,
+	})
+	if err != nil {
+		return nil, toAWSError(err)
+	}
+
+	output := &smv1.GetSecretValueOutput{
+		ARN:          result.ARN,
+		CreatedDate:  result.CreatedDate,
+		Name:         result.Name,
+		SecretBinary: result.SecretBinary,
+		SecretString: result.SecretString,
+		VersionId:    result.VersionId,
+	}
+	for i := range result.VersionStages {
+		output.VersionStages = append(output.VersionStages, &result.VersionStages[i])
+	}
+	return output, nil
+}
+
+// toAWSError converts a v2 API error into the v1 awserr form package
+// awssecret inspects, so that error codes, sentinels and request IDs are
+// reported the same way for both SDKs.
+//
+func toAWSError(err error) error {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+
+	aerr := awserr.New(apiErr.ErrorCode(), apiErr.ErrorMessage(), err)
+
+	var respErr *awshttp.ResponseError
+	if errors.As(err, &respErr) {
+		return awserr.NewRequestFailure(aerr, respErr.HTTPStatusCode(), respErr.ServiceRequestID())
+	}
+	return aerr
+}
+
+// GetStringSecret retrieves the named secret from AWS Secrets Manager and
+// returns it in its raw form.
+//
+func GetStringSecret(cfg aws.Config, secretName string) (secret string, err error) {
+	return GetStringSecretWithContext(context.Background(), cfg, secretName)
+}
+
+// GetStringSecretWithContext is the same as GetStringSecret, but uses the
+// supplied context for the underlying AWS request.
+//
+func GetStringSecretWithContext(ctx context.Context, cfg aws.Config, secretName string) (secret string, err error) {
+	return NewClient(cfg).GetStringWithContext(ctx, secretName)
+}
+
+// GetCredentialSecret retrieves and JSON-decodes a Credential stored in AWS
+// Secrets Manager.
+//
+func GetCredentialSecret(cfg aws.Config, secretName string) (cred *awssecret.Credential, err error) {
+	return GetCredentialSecretWithContext(context.Background(), cfg, secretName)
+}
+
+// GetCredentialSecretWithContext is the same as GetCredentialSecret, but
+// uses the supplied context for the underlying AWS request.
+//
+func GetCredentialSecretWithContext(ctx context.Context, cfg aws.Config, secretName string) (cred *awssecret.Credential, err error) {
+	return NewClient(cfg).GetCredentialWithContext(ctx, secretName)
+}
+
+// GetPostgresDSNSecret retrieves the named secret from AWS Secrets Manager
+// and converts it into a Postgres-compatible DSN string, as
+// awssecret.GetPostgresDSNSecret does.
+//
+func GetPostgresDSNSecret(cfg aws.Config, secretName string) (dsnStr string, err error) {
+	return GetPostgresDSNSecretWithContext(context.Background(), cfg, secretName)
+}
+
+// GetPostgresDSNSecretWithContext is the same as GetPostgresDSNSecret, but
+// uses the supplied context for the underlying AWS request.
+//
+func GetPostgresDSNSecretWithContext(ctx context.Context, cfg aws.Config, secretName string) (dsnStr string, err error) {
+	return NewClient(cfg).GetPostgresDSNWithContext(ctx, secretName)
+}
@@ -0,0 +1,197 @@
+package awssecretv2
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/adlio/awssecret"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	v1aws "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	smv1 "github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// stub is a hand-rolled GetSecretValueAPI answering from outputs, failing
+// with err if it is set. Every input is recorded.
+type stub struct {
+	outputs map[string]*secretsmanager.GetSecretValueOutput
+	err     error
+	inputs  []*secretsmanager.GetSecretValueInput
+}
+
+func (s *stub) GetSecretValue(ctx context.Context, input *secretsmanager.GetSecretValueInput, opts ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error) {
+	s.inputs = append(s.inputs, input)
+	if s.err != nil {
+		return nil, s.err
+	}
+	output, ok := s.outputs[aws.ToString(input.SecretId)]
+	if !ok {
+		return nil, &types.ResourceNotFoundException{Message: aws.String("not found")}
+	}
+	return output, nil
+}
+
+func TestAPIGetSecretValue(t *testing.T) {
+	created := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	s := &stub{outputs: map[string]*secretsmanager.GetSecretValueOutput{
+		"db": {
+			ARN:           aws.String("arn:aws:secretsmanager:us-east-1:123456789012:secret:db-AbCdEf"),
+			Name:          aws.String("db"),
+			CreatedDate:   aws.Time(created),
+			SecretString:  aws.String("hunter2"),
+			VersionId:     aws.String("v2"),
+			VersionStages: []string{"AWSCURRENT", "custom"},
+		},
+	}}
+
+	output, err := NewAPI(s).GetSecretValue(&smv1.GetSecretValueInput{
+		SecretId:     v1aws.String("db"),
+		VersionStage: v1aws.String("AWSCURRENT"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &smv1.GetSecretValueOutput{
+		ARN:           v1aws.String("arn:aws:secretsmanager:us-east-1:123456789012:secret:db-AbCdEf"),
+		Name:          v1aws.String("db"),
+		CreatedDate:   v1aws.Time(created),
+		SecretString:  v1aws.String("hunter2"),
+		VersionId:     v1aws.String("v2"),
+		VersionStages: v1aws.StringSlice([]string{"AWSCURRENT", "custom"}),
+	}
+	if !reflect.DeepEqual(output, want) {
+		t.Errorf("got %v, want %v", output, want)
+	}
+
+	input := s.inputs[0]
+	if aws.ToString(input.SecretId) != "db" || aws.ToString(input.VersionStage) != "AWSCURRENT" || input.VersionId != nil {
+		t.Errorf("input = %+v, want SecretId db and VersionStage AWSCURRENT", input)
+	}
+}
+
+func TestClientFunctions(t *testing.T) {
+	s := &stub{outputs: map[string]*secretsmanager.GetSecretValueOutput{
+		"plain": {Name: aws.String("plain"), SecretString: aws.String("hunter2")},
+		"db":    {Name: aws.String("db"), SecretString: aws.String(`{"host":"db.local","username":"admin","password":"pw","dbname":"app"}`)},
+	}}
+	client := awssecret.NewClientWithAPI(NewAPI(s))
+
+	str, err := client.GetString("plain")
+	if err != nil || str != "hunter2" {
+		t.Errorf("GetString = %q, %v; want hunter2", str, err)
+	}
+
+	cred, err := client.GetCredential("db")
+	if err != nil || cred.Username != "admin" || cred.Password != "pw" {
+		t.Errorf("GetCredential = %+v, %v; want admin/pw", cred, err)
+	}
+
+	dsn, err := client.GetPostgresDSN("db")
+	if want := "host=db.local dbname=app user=admin password=pw "; err != nil || dsn != want {
+		t.Errorf("GetPostgresDSN = %q, %v; want %q", dsn, err, want)
+	}
+
+	if _, err := client.GetString("missing"); !errors.Is(err, awssecret.ErrSecretNotFound) {
+		t.Errorf("err = %v, want ErrSecretNotFound", err)
+	}
+}
+
+func TestToAWSError(t *testing.T) {
+	notFound := &smithy.GenericAPIError{Code: "ResourceNotFoundException", Message: "can't find it"}
+	withResponse := &awshttp.ResponseError{
+		ResponseError: &smithyhttp.ResponseError{
+			Response: &smithyhttp.Response{Response: &http.Response{StatusCode: 400}},
+			Err:      notFound,
+		},
+		RequestID: "req-1234",
+	}
+	plain := errors.New("connection reset")
+
+	tests := []struct {
+		name     string
+		err      error
+		wantCode string
+		wantReq  string
+		wantHTTP int
+		wantNF   bool
+	}{
+		{"API error", notFound, "ResourceNotFoundException", "", 0, true},
+		{"API error with response", withResponse, "ResourceNotFoundException", "req-1234", 400, true},
+		{"decryption failure", &types.DecryptionFailure{Message: aws.String("no key")}, "DecryptionFailure", "", 0, false},
+		{"other error", plain, "", "", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := awssecret.NewClientWithAPI(NewAPI(&stub{err: tt.err}))
+			_, err := client.GetString("db")
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if got := errors.Is(err, awssecret.ErrSecretNotFound); got != tt.wantNF {
+				t.Errorf("errors.Is(err, ErrSecretNotFound) = %v, want %v", got, tt.wantNF)
+			}
+
+			var serr *awssecret.SecretError
+			if tt.wantCode == "" {
+				if errors.As(err, &serr) {
+					t.Errorf("err = %v is a *SecretError, want a plain error", err)
+				}
+				if !errors.Is(err, tt.err) {
+					t.Errorf("err = %v doesn't unwrap to the original error", err)
+				}
+				return
+			}
+			if !errors.As(err, &serr) {
+				t.Fatalf("err = %v, want a *SecretError", err)
+			}
+			if aerr, ok := serr.Unwrap().(awserr.Error); !ok || aerr.OrigErr() != tt.err {
+				t.Errorf("err = %v doesn't carry the original v2 error", serr.Unwrap())
+			}
+			if serr.Code != tt.wantCode || serr.RequestID != tt.wantReq || serr.StatusCode != tt.wantHTTP {
+				t.Errorf("got code %q, request ID %q, status %d; want %q, %q, %d",
+					serr.Code, serr.RequestID, serr.StatusCode, tt.wantCode, tt.wantReq, tt.wantHTTP)
+			}
+		})
+	}
+}
+
+func TestGetStringSecretWithConfig(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct{ SecretId string }
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		if body.SecretId != "db" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"__type": "ResourceNotFoundException", "message": "not found"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"Name": "db", "SecretString": "hunter2"})
+	}))
+	defer server.Close()
+
+	cfg := aws.Config{
+		Region:       "us-east-1",
+		Credentials:  aws.AnonymousCredentials{},
+		BaseEndpoint: aws.String(server.URL),
+	}
+
+	got, err := GetStringSecret(cfg, "db")
+	if err != nil || got != "hunter2" {
+		t.Errorf("got %q, %v; want hunter2", got, err)
+	}
+	if _, err := GetStringSecret(cfg, "missing"); !errors.Is(err, awssecret.ErrSecretNotFound) {
+		t.Errorf("err = %v, want ErrSecretNotFound", err)
+	}
+}
@@ -0,0 +1,46 @@
+// Package awssecretyaml decodes YAML secrets stored in AWS Secrets Manager,
+// for configuration which is kept as YAML rather than JSON:
+//
+//	type config struct {
+//		Host string `yaml:"host"`
+//		Port int    `yaml:"port"`
+//	}
+//	cfg, err := awssecretyaml.GetYAMLSecret[config](sess, "prod/config")
+//
+package awssecretyaml
+
+import (
+	"context"
+
+	"github.com/adlio/awssecret"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// GetYAMLSecret retrieves the named secret from AWS Secrets Manager and
+// YAML-decodes it into a new value of type T, as awssecret.GetJSONSecret
+// does for JSON. Any struct with the appropriate yaml tags can be used.
+//
+func GetYAMLSecret[T any](sess *session.Session, secretName string) (v *T, err error) {
+	return GetYAMLSecretWithContext[T](context.Background(), sess, secretName)
+}
+
+// GetYAMLSecretWithContext is the same as GetYAMLSecret, but uses the
+// supplied context for the underlying AWS request.
+//
+func GetYAMLSecretWithContext[T any](ctx context.Context, sess *session.Session, secretName string) (v *T, err error) {
+	var secret string
+	v = new(T)
+	secret, err = awssecret.GetStringSecretWithContext(ctx, sess, secretName)
+	if err != nil {
+		return v, errors.Wrapf(err, "Couldn't decode secret. Failed to retrieve secret.")
+	}
+
+	err = yaml.Unmarshal([]byte(secret), v)
+	if err != nil {
+		return v, errors.Wrapf(err, "Couldn't decode secret. Failed to decode YAML.")
+	}
+
+	return v, nil
+}
@@ -0,0 +1,86 @@
+package awssecretyaml
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/adlio/awssecret"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+// newSession returns a session whose Secrets Manager endpoint is a fake
+// server answering GetSecretValue from values. Missing names get a
+// ResourceNotFoundException.
+func newSession(t *testing.T, values map[string]string) *session.Session {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct{ SecretId string }
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		value, ok := values[body.SecretId]
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"__type": "ResourceNotFoundException", "message": "not found"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"Name": body.SecretId, "SecretString": value})
+	}))
+	t.Cleanup(server.Close)
+
+	sess, err := session.NewSession(&aws.Config{
+		Region:      aws.String("us-east-1"),
+		Credentials: credentials.AnonymousCredentials,
+		Endpoint:    aws.String(server.URL),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sess
+}
+
+func TestGetYAMLSecret(t *testing.T) {
+	type config struct {
+		Host  string   `yaml:"host"`
+		Port  int      `yaml:"port"`
+		Hosts []string `yaml:"hosts"`
+	}
+	sess := newSession(t, map[string]string{
+		"yaml":    "host: db.local\nport: 5432\nhosts:\n  - a\n  - b\n",
+		"json":    `{"host":"db.local","port":5432}`,
+		"invalid": "host: [unterminated",
+		"wrong":   "port: not-a-number\n",
+	})
+
+	tests := []struct {
+		secret     string
+		want       config
+		wantErr    bool
+		wantNotFnd bool
+	}{
+		{"yaml", config{Host: "db.local", Port: 5432, Hosts: []string{"a", "b"}}, false, false},
+		{"json", config{Host: "db.local", Port: 5432}, false, false},
+		{"invalid", config{}, true, false},
+		{"wrong", config{}, true, false},
+		{"missing", config{}, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.secret, func(t *testing.T) {
+			got, err := GetYAMLSecret[config](sess, tt.secret)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := errors.Is(err, awssecret.ErrSecretNotFound); got != tt.wantNotFnd {
+				t.Errorf("errors.Is(err, ErrSecretNotFound) = %v, want %v", got, tt.wantNotFnd)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("got %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
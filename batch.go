@@ -0,0 +1,130 @@
+package awssecret
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/pkg/errors"
+)
+
+// BatchGetSecretValueAPI is the subset of the AWS Secrets Manager client
+// used by GetStringSecretsBatch.
+//
+type BatchGetSecretValueAPI interface {
+	BatchGetSecretValueWithContext(context.Context, *secretsmanager.BatchGetSecretValueInput, ...request.Option) (*secretsmanager.BatchGetSecretValueOutput, error)
+}
+
+// maxBatchSize is the largest number of secrets BatchGetSecretValue accepts
+// in a single SecretIdList.
+const maxBatchSize = 20
+
+// GetStringSecretsBatch retrieves the named secrets from AWS Secrets Manager
+// using BatchGetSecretValue, which fetches up to 20 secrets per request, and
+// returns their raw values keyed by the names supplied. Secrets which AWS
+// reports errors for are returned in a SecretErrors alongside the values
+// which could be read.
+//
+func GetStringSecretsBatch(sess *session.Session, secretNames []string) (secrets map[string]string, err error) {
+	return GetStringSecretsBatchWithContext(context.Background(), sess, secretNames)
+}
+
+// GetStringSecretsBatchWithContext is the same as GetStringSecretsBatch, but
+// uses the supplied context for the underlying AWS requests.
+//
+func GetStringSecretsBatchWithContext(ctx context.Context, sess *session.Session, secretNames []string) (secrets map[string]string, err error) {
+	svc, err := newService(sess, ClientOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return GetStringSecretsBatchWithClientContext(ctx, svc, secretNames)
+}
+
+// GetStringSecretsBatchWithClientContext is the same as
+// GetStringSecretsBatchWithContext, but uses the supplied client.
+//
+func GetStringSecretsBatchWithClientContext(ctx context.Context, client BatchGetSecretValueAPI, secretNames []string) (secrets map[string]string, err error) {
+	secrets = make(map[string]string, len(secretNames))
+	errs := SecretErrors{}
+
+	for _, chunk := range batchChunks(secretNames) {
+		// Results identify secrets by name and ARN, so map both back to
+		// whichever form the caller asked for.
+		requested := make(map[string]string, len(chunk))
+		for _, name := range chunk {
+			requested[name] = name
+		}
+		key := func(ids ...*string) string {
+			for _, id := range ids {
+				if name, ok := requested[aws.StringValue(id)]; ok {
+					return name
+				}
+			}
+			return aws.StringValue(ids[0])
+		}
+
+		input := &secretsmanager.BatchGetSecretValueInput{
+			SecretIdList: aws.StringSlice(chunk),
+		}
+		for {
+			output, err := client.BatchGetSecretValueWithContext(ctx, input)
+			if err != nil {
+				return secrets, errors.Wrapf(mapAWSError("", err), "Failed to batch get secrets from AWS Secrets Manager")
+			}
+
+			for _, entry := range output.SecretValues {
+				name := key(entry.Name, entry.ARN)
+				if entry.SecretString == nil {
+					errs[name] = noStringError(name, entry.SecretBinary)
+					continue
+				}
+				secrets[name] = *entry.SecretString
+			}
+
+			for _, e := range output.Errors {
+				aerr := awserr.New(aws.StringValue(e.ErrorCode), aws.StringValue(e.Message), nil)
+				name := key(e.SecretId)
+				errs[name] = mapAWSError(name, aerr)
+			}
+
+			if aws.StringValue(output.NextToken) == "" {
+				break
+			}
+			input.NextToken = output.NextToken
+		}
+	}
+
+	if len(errs) > 0 {
+		return secrets, errs
+	}
+	return secrets, nil
+}
+
+// batchChunks splits names into chunks of at most maxBatchSize. Names are
+// grouped by the region of their ARN (friendly names form their own group)
+// so that each chunk can be sent to a single region.
+//
+func batchChunks(names []string) (chunks [][]string) {
+	var regions []string
+	groups := make(map[string][]string)
+	for _, name := range names {
+		region := secretARNRegion(name)
+		if _, ok := groups[region]; !ok {
+			regions = append(regions, region)
+		}
+		groups[region] = append(groups[region], name)
+	}
+
+	for _, region := range regions {
+		group := groups[region]
+		for len(group) > maxBatchSize {
+			chunks = append(chunks, group[:maxBatchSize])
+			group = group[maxBatchSize:]
+		}
+		chunks = append(chunks, group)
+	}
+	return chunks
+}
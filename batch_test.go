@@ -0,0 +1,139 @@
+package awssecret
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+)
+
+// batchStub answers BatchGetSecretValue from values, reporting a
+// ResourceNotFoundException for any other ID. Results are split into pages
+// of pageSize entries when it is positive.
+type batchStub struct {
+	values   map[string]string
+	pageSize int
+	requests []*secretsmanager.BatchGetSecretValueInput
+}
+
+func (s *batchStub) BatchGetSecretValueWithContext(ctx context.Context, input *secretsmanager.BatchGetSecretValueInput, _ ...request.Option) (*secretsmanager.BatchGetSecretValueOutput, error) {
+	s.requests = append(s.requests, input)
+
+	ids := aws.StringValueSlice(input.SecretIdList)
+	start := 0
+	if input.NextToken != nil {
+		for i, id := range ids {
+			if id == *input.NextToken {
+				start = i
+			}
+		}
+	}
+	end := len(ids)
+	if s.pageSize > 0 && start+s.pageSize < end {
+		end = start + s.pageSize
+	}
+
+	output := &secretsmanager.BatchGetSecretValueOutput{}
+	for _, id := range ids[start:end] {
+		v, ok := s.values[id]
+		if !ok {
+			output.Errors = append(output.Errors, &secretsmanager.APIErrorType{
+				SecretId:  aws.String(id),
+				ErrorCode: aws.String(secretsmanager.ErrCodeResourceNotFoundException),
+				Message:   aws.String("not found"),
+			})
+			continue
+		}
+		output.SecretValues = append(output.SecretValues, &secretsmanager.SecretValueEntry{
+			Name:         aws.String(id),
+			SecretString: aws.String(v),
+		})
+	}
+	if end < len(ids) {
+		output.NextToken = aws.String(ids[end])
+	}
+	return output, nil
+}
+
+func TestGetStringSecretsBatchWithClientContext(t *testing.T) {
+	many := make([]string, 45)
+	manyValues := make(map[string]string, len(many))
+	for i := range many {
+		many[i] = string(rune('A' + i))
+		manyValues[many[i]] = many[i]
+	}
+
+	tests := []struct {
+		name         string
+		stub         *batchStub
+		secrets      []string
+		want         map[string]string
+		wantFailed   []string
+		wantRequests int
+	}{
+		{
+			name:         "single batch",
+			stub:         &batchStub{values: map[string]string{"a": "1", "b": "2"}},
+			secrets:      []string{"a", "b"},
+			want:         map[string]string{"a": "1", "b": "2"},
+			wantRequests: 1,
+		},
+		{
+			name:         "per-secret errors",
+			stub:         &batchStub{values: map[string]string{"a": "1"}},
+			secrets:      []string{"a", "missing"},
+			want:         map[string]string{"a": "1"},
+			wantFailed:   []string{"missing"},
+			wantRequests: 1,
+		},
+		{
+			name:         "chunks of 20",
+			stub:         &batchStub{values: manyValues},
+			secrets:      many,
+			want:         manyValues,
+			wantRequests: 3,
+		},
+		{
+			name:         "pages are followed",
+			stub:         &batchStub{values: map[string]string{"a": "1", "b": "2", "c": "3"}, pageSize: 2},
+			secrets:      []string{"a", "b", "c"},
+			want:         map[string]string{"a": "1", "b": "2", "c": "3"},
+			wantRequests: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetStringSecretsBatchWithClientContext(context.Background(), tt.stub, tt.secrets)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			if len(tt.stub.requests) != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", len(tt.stub.requests), tt.wantRequests)
+			}
+			for _, input := range tt.stub.requests {
+				if len(input.SecretIdList) > maxBatchSize {
+					t.Errorf("request for %d secrets, want at most %d", len(input.SecretIdList), maxBatchSize)
+				}
+			}
+			if tt.wantFailed == nil {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			var errs SecretErrors
+			if !errors.As(err, &errs) {
+				t.Fatalf("err = %v, want SecretErrors", err)
+			}
+			for _, name := range tt.wantFailed {
+				if !errors.Is(errs[name], ErrSecretNotFound) {
+					t.Errorf("errs[%q] = %v, want ErrSecretNotFound", name, errs[name])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package awssecret
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/pkg/errors"
+)
+
+// GetBinarySecret retrieves the named secret from AWS Secrets Manager and
+// returns the raw bytes of a secret stored as SecretBinary. The SDK already
+// base64-decodes SecretBinary off the wire, so the bytes are returned exactly
+// as they were originally stored. A secret stored as a SecretString returns
+// an error matching ErrSecretIsString.
+//
+func GetBinarySecret(sess *session.Session, secretName string) (secret []byte, err error) {
+	return GetBinarySecretWithContext(context.Background(), sess, secretName)
+}
+
+// GetBinarySecretWithContext is the same as GetBinarySecret, but uses the
+// supplied context for the underlying AWS request.
+//
+func GetBinarySecretWithContext(ctx context.Context, sess *session.Session, secretName string) (secret []byte, err error) {
+	c := clientFor(sess)
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	result, err := getSecretValue(ctx, c.api, newGetSecretValueInput(secretName, "", ""))
+	if err != nil {
+		return nil, err
+	}
+
+	if result.SecretBinary != nil {
+		return result.SecretBinary, nil
+	}
+
+	if result.SecretString != nil {
+		return nil, errors.Wrapf(ErrSecretIsString, "Secret %q", secretName)
+	}
+
+	return nil, errors.Wrapf(ErrSecretEmpty, "Secret %q", secretName)
+}
+
+// WriteBinarySecretTo retrieves the named secret as GetBinarySecret does and
+// writes its bytes to w, returning the number of bytes written. It is a
+// convenience for saving binary secrets, such as keystores, to a file: the
+// whole secret is still read into memory before anything is written.
+//
+func WriteBinarySecretTo(sess *session.Session, secretName string, w io.Writer) (n int64, err error) {
+	return WriteBinarySecretToWithContext(context.Background(), sess, secretName, w)
+}
+
+// WriteBinarySecretToWithContext is the same as WriteBinarySecretTo, but
+// uses the supplied context for the underlying AWS request.
+//
+func WriteBinarySecretToWithContext(ctx context.Context, sess *session.Session, secretName string, w io.Writer) (n int64, err error) {
+	secret, err := GetBinarySecretWithContext(ctx, sess, secretName)
+	if err != nil {
+		return 0, err
+	}
+
+	written, err := w.Write(secret)
+	if err != nil {
+		return int64(written), errors.Wrapf(err, "Failed to write secret %q", secretName)
+	}
+	return int64(written), nil
+}
+
+// GetSecretBytes retrieves the named secret from AWS Secrets Manager and
+// returns its value as bytes, whichever way it was stored: the SecretString
+// if present, otherwise the SecretBinary. An error is only returned if the
+// secret has neither.
+//
+func GetSecretBytes(sess *session.Session, secretName string) (secret []byte, err error) {
+	return GetSecretBytesWithContext(context.Background(), sess, secretName)
+}
+
+// GetSecretBytesWithContext is the same as GetSecretBytes, but uses the
+// supplied context for the underlying AWS request.
+//
+func GetSecretBytesWithContext(ctx context.Context, sess *session.Session, secretName string) (secret []byte, err error) {
+	return getSecretBytes(ctx, clientFor(sess), secretName)
+}
+
+func getSecretBytes(ctx context.Context, c *Client, secretName string) (secret []byte, err error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	result, err := getSecretValue(ctx, c.api, newGetSecretValueInput(c.secretID(secretName), "", ""))
+	if err != nil {
+		return nil, err
+	}
+
+	if result.SecretString != nil {
+		return []byte(*result.SecretString), nil
+	}
+
+	if result.SecretBinary != nil {
+		return result.SecretBinary, nil
+	}
+
+	return nil, errors.Wrapf(ErrSecretEmpty, "Secret %q", secretName)
+}
+
+// GetDecodedStringSecret retrieves the named secret from AWS Secrets Manager
+// and base64-decodes its SecretString, for tools which store binary payloads
+// as base64 text rather than as SecretBinary. An error is returned if the
+// value isn't valid standard base64. Use GetStringSecret to read the text
+// without decoding it.
+//
+func GetDecodedStringSecret(sess *session.Session, secretName string) (secret []byte, err error) {
+	return GetDecodedStringSecretWithContext(context.Background(), sess, secretName)
+}
+
+// GetDecodedStringSecretWithContext is the same as GetDecodedStringSecret,
+// but uses the supplied context for the underlying AWS request.
+//
+func GetDecodedStringSecretWithContext(ctx context.Context, sess *session.Session, secretName string) (secret []byte, err error) {
+	str, err := GetStringSecretWithContext(ctx, sess, secretName)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err = base64.StdEncoding.DecodeString(str)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Secret %q is not valid base64", secretName)
+	}
+
+	return secret, nil
+}
@@ -0,0 +1,181 @@
+package awssecret
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/adlio/awssecret/awssecrettest"
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+func TestGetBinarySecret(t *testing.T) {
+	url, _ := newSecretsManagerServer(t, func(req smRequest) (int, interface{}) {
+		switch req.Body["SecretId"] {
+		case "keystore":
+			return http.StatusOK, map[string]interface{}{"Name": "keystore", "SecretBinary": []byte{0x00, 0xff, 0x10}}
+		case "text":
+			return http.StatusOK, map[string]interface{}{"Name": "text", "SecretString": "hunter2"}
+		}
+		return http.StatusBadRequest, map[string]string{"__type": "ResourceNotFoundException", "message": "not found"}
+	})
+	sess := testSession(t).Copy(aws.NewConfig().WithEndpoint(url))
+
+	tests := []struct {
+		name    string
+		secret  string
+		want    []byte
+		wantErr error
+	}{
+		{"binary", "keystore", []byte{0x00, 0xff, 0x10}, nil},
+		{"string", "text", nil, ErrSecretIsString},
+		{"missing", "missing", nil, ErrSecretNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetBinarySecret(sess, tt.secret)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := GetStringSecret(sess, "keystore"); !errors.Is(err, ErrSecretIsBinary) {
+		t.Errorf("GetStringSecret of a binary secret: err = %v, want ErrSecretIsBinary", err)
+	}
+}
+
+func TestGetDecodedStringSecret(t *testing.T) {
+	sess := newStringSecretsSession(t, map[string]string{
+		"encoded": "aHVudGVyMg==",
+		"binary":  "AP8Q",
+		"plain":   "hunter2!",
+	})
+
+	tests := []struct {
+		secret  string
+		want    []byte
+		wantErr bool
+	}{
+		{"encoded", []byte("hunter2"), false},
+		{"binary", []byte{0x00, 0xff, 0x10}, false},
+		{"plain", nil, true},
+		{"missing", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.secret, func(t *testing.T) {
+			got, err := GetDecodedStringSecret(sess, tt.secret)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if string(got) != string(tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetSecretBytes(t *testing.T) {
+	url, _ := newSecretsManagerServer(t, func(req smRequest) (int, interface{}) {
+		name, _ := req.Body["SecretId"].(string)
+		output := map[string]interface{}{"Name": name}
+		switch name {
+		case "string":
+			output["SecretString"] = "hunter2"
+		case "binary":
+			output["SecretBinary"] = []byte{0x00, 0xff, 0x10}
+		case "both":
+			output["SecretString"] = "hunter2"
+			output["SecretBinary"] = []byte{0x00}
+		case "empty-string":
+			output["SecretString"] = ""
+		case "neither":
+		default:
+			return http.StatusBadRequest, map[string]string{"__type": "ResourceNotFoundException", "message": "not found"}
+		}
+		return http.StatusOK, output
+	})
+	sess := testSession(t).Copy(aws.NewConfig().WithEndpoint(url))
+
+	tests := []struct {
+		secret  string
+		want    []byte
+		wantErr error
+	}{
+		{"string", []byte("hunter2"), nil},
+		{"binary", []byte{0x00, 0xff, 0x10}, nil},
+		{"both", []byte("hunter2"), nil},
+		{"empty-string", []byte{}, nil},
+		{"neither", nil, ErrSecretEmpty},
+		{"missing", nil, ErrSecretNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.secret, func(t *testing.T) {
+			got, err := GetSecretBytes(sess, tt.secret)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// failingWriter accepts at most limit bytes, then fails.
+type failingWriter struct{ limit int }
+
+func (w failingWriter) Write(p []byte) (int, error) {
+	if len(p) > w.limit {
+		return w.limit, errors.New("disk full")
+	}
+	return len(p), nil
+}
+
+func TestWriteBinarySecretTo(t *testing.T) {
+	keystore := bytes.Repeat([]byte{0x00, 0xff, 0x10}, 1000)
+	fake := awssecrettest.NewFakeClient()
+	fake.SetBinary("keystore", keystore)
+	fake.SetString("text", "hunter2")
+	useDefaultAPI(t, fake)
+
+	var buf bytes.Buffer
+	n, err := WriteBinarySecretTo(nil, "keystore", &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(len(keystore)) || !bytes.Equal(buf.Bytes(), keystore) {
+		t.Errorf("wrote %d bytes, want the %d byte keystore", n, len(keystore))
+	}
+
+	tests := []struct {
+		name    string
+		secret  string
+		w       io.Writer
+		wantN   int64
+		wantErr error
+	}{
+		{"string secret", "text", &bytes.Buffer{}, 0, ErrSecretIsString},
+		{"missing", "missing", &bytes.Buffer{}, 0, ErrSecretNotFound},
+		{"short write", "keystore", failingWriter{limit: 10}, 10, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := WriteBinarySecretTo(nil, tt.secret, tt.w)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+			if n != tt.wantN {
+				t.Errorf("n = %d, want %d", n, tt.wantN)
+			}
+		})
+	}
+}
@@ -0,0 +1,313 @@
+package awssecret
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+)
+
+// CachingClient wraps a SecretsManagerAPI and keeps successful GetSecretValue
+// results (and optionally not-found errors) in memory for a fixed TTL, so
+// repeated reads of the same secret don't each make a request to AWS. The
+// number of entries can be bounded, in which case the least recently used
+// entry is evicted to make room. It is safe for concurrent use and can be
+// passed to any of the WithClient functions.
+//
+type CachingClient struct {
+	client SecretsManagerAPI
+	opts   CacheOptions
+	now    func() time.Time
+
+	// entries indexes the elements of lru, whose values are *cacheItems
+	// ordered from most to least recently used.
+	mu      sync.Mutex
+	entries map[cacheKey]*list.Element
+	lru     *list.List
+
+	hits      atomic.Int64
+	misses    atomic.Int64
+	evictions atomic.Int64
+	staleHits atomic.Int64
+}
+
+// CacheStats is a snapshot of a CachingClient's counters.
+//
+type CacheStats struct {
+	// Hits counts reads served from the cache.
+	Hits int64
+
+	// Misses counts reads passed through to the wrapped client.
+	Misses int64
+
+	// Evictions counts cached entries discarded because they expired,
+	// were invalidated, or were the least recently used when the cache was
+	// full.
+	Evictions int64
+
+	// StaleHits counts expired values served because refreshing them
+	// failed. They are also counted as Misses.
+	StaleHits int64
+}
+
+// cacheKey identifies a cached GetSecretValue result. A version pinned by
+// VersionId is cached separately from the same secret's staging labels, so
+// reading it never replaces the AWSCURRENT entry.
+//
+type cacheKey struct {
+	secretID     string
+	versionStage string
+	versionID    string
+}
+
+// newCacheKey returns the key for input. A request naming neither a
+// VersionId nor a VersionStage reads AWSCURRENT, so it shares that entry.
+//
+func newCacheKey(input *secretsmanager.GetSecretValueInput) cacheKey {
+	key := cacheKey{
+		secretID:     aws.StringValue(input.SecretId),
+		versionStage: aws.StringValue(input.VersionStage),
+		versionID:    aws.StringValue(input.VersionId),
+	}
+	if key.versionStage == "" && key.versionID == "" {
+		key.versionStage = DefaultVersionStage
+	}
+	return key
+}
+
+type cacheEntry struct {
+	output  *secretsmanager.GetSecretValueOutput
+	err     error
+	expires time.Time
+
+	// retryAt is set when the expired entry has been served stale, and is
+	// when refreshing it will next be attempted.
+	retryAt time.Time
+}
+
+type cacheItem struct {
+	key   cacheKey
+	entry cacheEntry
+}
+
+// CacheOptions configures a CachingClient.
+//
+type CacheOptions struct {
+	// TTL is how long a successfully retrieved value is served from the
+	// cache.
+	TTL time.Duration
+
+	// NegativeTTL is how long a ResourceNotFoundException is served from
+	// the cache, so that polling for a secret which doesn't exist yet
+	// doesn't hammer the API. Zero disables negative caching.
+	NegativeTTL time.Duration
+
+	// MaxEntries bounds the number of cached entries. When a new entry
+	// would exceed it, the least recently used entry is evicted. Zero means
+	// no limit.
+	MaxEntries int
+
+	// ServeStaleOnError returns the last successfully retrieved value,
+	// instead of an error, when refreshing an expired entry fails (e.g.
+	// because AWS is throttling requests). The FetchEvent for such a read
+	// has Stale set.
+	ServeStaleOnError bool
+
+	// MaxStale limits how long past its TTL a value may be served by
+	// ServeStaleOnError. Zero means no limit.
+	MaxStale time.Duration
+
+	// StaleRetryInterval is how long a value served by ServeStaleOnError
+	// keeps being served before refreshing it is attempted again, so that
+	// a failing API isn't called on every read. Zero uses TTL.
+	StaleRetryInterval time.Duration
+}
+
+// NewCachingClient returns a CachingClient which serves cached values from
+// client for up to ttl after they are fetched.
+//
+func NewCachingClient(client SecretsManagerAPI, ttl time.Duration) *CachingClient {
+	return NewCachingClientWithOptions(client, CacheOptions{TTL: ttl})
+}
+
+// NewCachingClientWithOptions returns a CachingClient configured by opts.
+//
+func NewCachingClientWithOptions(client SecretsManagerAPI, opts CacheOptions) *CachingClient {
+	return &CachingClient{
+		client:  client,
+		opts:    opts,
+		now:     time.Now,
+		entries: make(map[cacheKey]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+// GetSecretValue implements SecretsManagerAPI, serving the result from the
+// cache when an unexpired entry exists.
+//
+func (c *CachingClient) GetSecretValue(input *secretsmanager.GetSecretValueInput) (*secretsmanager.GetSecretValueOutput, error) {
+	return c.GetSecretValueWithContext(context.Background(), input)
+}
+
+// GetSecretValueWithContext is the same as GetSecretValue, but passes the
+// supplied context to the wrapped client on a cache miss.
+//
+func (c *CachingClient) GetSecretValueWithContext(ctx context.Context, input *secretsmanager.GetSecretValueInput, opts ...request.Option) (*secretsmanager.GetSecretValueOutput, error) {
+	key := newCacheKey(input)
+
+	entry, ok := c.get(key)
+	if ok && c.now().Before(entry.expires) {
+		c.hits.Add(1)
+		markCacheHit(ctx)
+		return entry.output, entry.err
+	}
+	if ok && c.now().Before(entry.retryAt) {
+		c.staleHits.Add(1)
+		markStale(ctx)
+		return entry.output, nil
+	}
+	c.misses.Add(1)
+
+	output, err := callGetSecretValue(ctx, c.client, input, opts...)
+	if ok && err != nil && c.canServeStale(entry) {
+		c.staleHits.Add(1)
+		markStale(ctx)
+		c.retryLater(key, entry)
+		return entry.output, nil
+	}
+	if ok {
+		c.evict(key, entry)
+	}
+	if err != nil {
+		if c.opts.NegativeTTL > 0 && isAWSErrorCode(err, secretsmanager.ErrCodeResourceNotFoundException) {
+			c.store(key, cacheEntry{err: err, expires: c.now().Add(c.opts.NegativeTTL)})
+		}
+		return nil, err
+	}
+
+	c.store(key, cacheEntry{output: output, expires: c.now().Add(c.opts.TTL)})
+
+	return output, nil
+}
+
+// Invalidate removes every cached version of the named secret, forcing the
+// next read to fetch it from AWS.
+//
+func (c *CachingClient) Invalidate(secretName string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, elem := range c.entries {
+		if key.secretID == secretName {
+			c.remove(elem)
+		}
+	}
+}
+
+// InvalidateAll empties the cache.
+//
+func (c *CachingClient) InvalidateAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.evictions.Add(int64(len(c.entries)))
+	c.entries = make(map[cacheKey]*list.Element)
+	c.lru.Init()
+}
+
+// Stats returns a snapshot of the cache's hit, miss and eviction counts.
+//
+func (c *CachingClient) Stats() CacheStats {
+	return CacheStats{
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions.Load(),
+		StaleHits: c.staleHits.Load(),
+	}
+}
+
+// canServeStale reports whether the expired entry may be served in place
+// of an error.
+//
+func (c *CachingClient) canServeStale(entry cacheEntry) bool {
+	if !c.opts.ServeStaleOnError || entry.err != nil {
+		return false
+	}
+	return c.opts.MaxStale == 0 || c.now().Before(entry.expires.Add(c.opts.MaxStale))
+}
+
+// retryLater postpones refreshing the stale entry for key by
+// StaleRetryInterval, without serving it past MaxStale, unless another
+// reader has already replaced it.
+//
+func (c *CachingClient) retryLater(key cacheKey, stale cacheEntry) {
+	interval := c.opts.StaleRetryInterval
+	if interval == 0 {
+		interval = c.opts.TTL
+	}
+	retryAt := c.now().Add(interval)
+	if c.opts.MaxStale > 0 {
+		if limit := stale.expires.Add(c.opts.MaxStale); retryAt.After(limit) {
+			retryAt = limit
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[key]; ok && elem.Value.(*cacheItem).entry.expires.Equal(stale.expires) {
+		elem.Value.(*cacheItem).entry.retryAt = retryAt
+	}
+}
+
+// evict removes the expired entry for key, unless another reader has
+// already replaced it.
+//
+func (c *CachingClient) evict(key cacheKey, expired cacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[key]; ok && elem.Value.(*cacheItem).entry.expires.Equal(expired.expires) {
+		c.remove(elem)
+	}
+}
+
+// get returns the entry for key, marking it as the most recently used.
+//
+func (c *CachingClient) get(key cacheKey) (entry cacheEntry, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		return cacheEntry{}, false
+	}
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*cacheItem).entry, true
+}
+
+// store sets the entry for key as the most recently used, evicting the
+// least recently used entry if the cache is over MaxEntries.
+//
+func (c *CachingClient) store(key cacheKey, entry cacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		elem.Value.(*cacheItem).entry = entry
+		c.lru.MoveToFront(elem)
+		return
+	}
+
+	c.entries[key] = c.lru.PushFront(&cacheItem{key: key, entry: entry})
+	if c.opts.MaxEntries > 0 && c.lru.Len() > c.opts.MaxEntries {
+		c.remove(c.lru.Back())
+	}
+}
+
+// remove evicts elem. The caller must hold c.mu.
+//
+func (c *CachingClient) remove(elem *list.Element) {
+	delete(c.entries, elem.Value.(*cacheItem).key)
+	c.lru.Remove(elem)
+	c.evictions.Add(1)
+}
@@ -0,0 +1,468 @@
+package awssecret
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+)
+
+// fakeClock is a manually advanced replacement for time.Now.
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+// newTestCache returns a CachingClient wrapping api whose clock only moves
+// when the returned fakeClock is advanced.
+func newTestCache(api SecretsManagerAPI, opts CacheOptions) (*CachingClient, *fakeClock) {
+	clock := &fakeClock{t: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	c := NewCachingClientWithOptions(api, opts)
+	c.now = clock.now
+	return c, clock
+}
+
+func getInput(secretName string) *secretsmanager.GetSecretValueInput {
+	return &secretsmanager.GetSecretValueInput{SecretId: aws.String(secretName)}
+}
+
+func TestCachingClientTTL(t *testing.T) {
+	tests := []struct {
+		name      string
+		reads     []string
+		advance   time.Duration
+		wantCalls int
+	}{
+		{"repeated read is cached", []string{"a", "a", "a"}, 0, 1},
+		{"different secrets are cached separately", []string{"a", "b", "a", "b"}, 0, 2},
+		{"read after expiry is refetched", []string{"a", "a"}, time.Minute, 2},
+		{"read just before expiry is cached", []string{"a", "a"}, time.Minute - time.Nanosecond, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := newStringStub(map[string]string{"a": "1", "b": "2"})
+			c, clock := newTestCache(stub, CacheOptions{TTL: time.Minute})
+
+			for _, name := range tt.reads {
+				if _, err := c.GetSecretValue(getInput(name)); err != nil {
+					t.Fatal(err)
+				}
+				clock.advance(tt.advance)
+			}
+			if n := stub.calls(); n != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", n, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestCachingClientDoesNotCacheErrors(t *testing.T) {
+	stub := newStringStub(nil)
+	c, _ := newTestCache(stub, CacheOptions{TTL: time.Minute})
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.GetSecretValue(getInput("missing")); err == nil {
+			t.Fatal("expected an error")
+		}
+	}
+	if n := stub.calls(); n != 2 {
+		t.Errorf("calls = %d, want 2", n)
+	}
+}
+
+func TestCachingClientInvalidate(t *testing.T) {
+	stub := newStringStub(map[string]string{"a": "1", "b": "2"})
+	c, _ := newTestCache(stub, CacheOptions{TTL: time.Minute})
+
+	c.GetSecretValue(getInput("a"))
+	c.GetSecretValue(getInput("b"))
+	c.Invalidate("a")
+	c.GetSecretValue(getInput("a"))
+	c.GetSecretValue(getInput("b"))
+	if n := stub.calls(); n != 3 {
+		t.Errorf("calls after Invalidate = %d, want 3", n)
+	}
+
+	c.InvalidateAll()
+	c.GetSecretValue(getInput("a"))
+	c.GetSecretValue(getInput("b"))
+	if n := stub.calls(); n != 5 {
+		t.Errorf("calls after InvalidateAll = %d, want 5", n)
+	}
+}
+
+func TestCachingClientWithPackageFunctions(t *testing.T) {
+	stub := newStringStub(map[string]string{"db": "hunter2"})
+	c, _ := newTestCache(stub, CacheOptions{TTL: time.Minute})
+
+	for i := 0; i < 3; i++ {
+		v, err := GetStringSecretWithClient(c, "db")
+		if err != nil || v != "hunter2" {
+			t.Fatalf("got %q, %v", v, err)
+		}
+	}
+	if n := stub.calls(); n != 1 {
+		t.Errorf("calls = %d, want 1", n)
+	}
+}
+
+func TestCachingClientNegativeTTL(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      CacheOptions
+		advance   time.Duration
+		wantCalls int
+	}{
+		{"disabled", CacheOptions{TTL: time.Minute}, 0, 3},
+		{"within negative TTL", CacheOptions{TTL: time.Minute, NegativeTTL: 5 * time.Second}, time.Second, 1},
+		{"after negative TTL", CacheOptions{TTL: time.Minute, NegativeTTL: 5 * time.Second}, 5 * time.Second, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := newStringStub(nil)
+			c, clock := newTestCache(stub, tt.opts)
+
+			for i := 0; i < 3; i++ {
+				_, err := c.GetSecretValue(getInput("missing"))
+				if !isAWSErrorCode(err, secretsmanager.ErrCodeResourceNotFoundException) {
+					t.Fatalf("err = %v, want ResourceNotFoundException", err)
+				}
+				clock.advance(tt.advance)
+			}
+			if n := stub.calls(); n != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", n, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestCachingClientNegativeTTLSecretAppears(t *testing.T) {
+	values := map[string]string{}
+	stub := newStringStub(values)
+	c, clock := newTestCache(stub, CacheOptions{TTL: time.Minute, NegativeTTL: 5 * time.Second})
+
+	if _, err := c.GetSecretValue(getInput("db")); err == nil {
+		t.Fatal("expected an error")
+	}
+	values["db"] = "hunter2"
+
+	if _, err := c.GetSecretValue(getInput("db")); err == nil {
+		t.Fatal("expected the cached not-found error within the negative TTL")
+	}
+
+	clock.advance(5 * time.Second)
+	output, err := c.GetSecretValue(getInput("db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := aws.StringValue(output.SecretString); got != "hunter2" {
+		t.Errorf("got %q, want hunter2", got)
+	}
+
+	clock.advance(30 * time.Second)
+	if _, err := c.GetSecretValue(getInput("db")); err != nil {
+		t.Fatal(err)
+	}
+	if n := stub.calls(); n != 2 {
+		t.Errorf("calls = %d, want 2", n)
+	}
+}
+
+func TestCachingClientNegativeTTLOnlyCachesNotFound(t *testing.T) {
+	stub := newErrorStub(awserr.New(secretsmanager.ErrCodeInternalServiceError, "failed", nil))
+	c, _ := newTestCache(stub, CacheOptions{TTL: time.Minute, NegativeTTL: time.Minute})
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.GetSecretValue(getInput("db")); err == nil {
+			t.Fatal("expected an error")
+		}
+	}
+	if n := stub.calls(); n != 2 {
+		t.Errorf("calls = %d, want 2", n)
+	}
+}
+
+func TestCachingClientKeysByVersion(t *testing.T) {
+	const oldID, newID = "old-version", "new-version"
+	byID := map[string]string{oldID: "old", newID: "new"}
+	byStage := map[string]string{"AWSCURRENT": "new", "AWSPREVIOUS": "old"}
+	stub := &stubClient{get: func(input *secretsmanager.GetSecretValueInput) (*secretsmanager.GetSecretValueOutput, error) {
+		stage := aws.StringValue(input.VersionStage)
+		if stage == "" {
+			stage = "AWSCURRENT"
+		}
+		v, ok := byID[aws.StringValue(input.VersionId)]
+		if !ok {
+			v = byStage[stage]
+		}
+		return &secretsmanager.GetSecretValueOutput{Name: input.SecretId, SecretString: aws.String(v)}, nil
+	}}
+	c, _ := newTestCache(stub, CacheOptions{TTL: time.Minute})
+
+	reads := []struct {
+		name      string
+		versionID string
+		stage     string
+		want      string
+		wantCalls int
+	}{
+		{"current", "", "", "new", 1},
+		{"pinned old version", oldID, "", "old", 2},
+		{"current after pinned", "", "", "new", 2},
+		{"explicit AWSCURRENT", "", "AWSCURRENT", "new", 2},
+		{"AWSPREVIOUS", "", "AWSPREVIOUS", "old", 3},
+		{"pinned new version", newID, "", "new", 4},
+		{"pinned old version again", oldID, "", "old", 4},
+		{"current again", "", "", "new", 4},
+	}
+	for _, r := range reads {
+		input := getInput("db")
+		if r.versionID != "" {
+			input.VersionId = aws.String(r.versionID)
+		}
+		if r.stage != "" {
+			input.VersionStage = aws.String(r.stage)
+		}
+
+		output, err := c.GetSecretValue(input)
+		if err != nil {
+			t.Fatalf("%s: %v", r.name, err)
+		}
+		if got := aws.StringValue(output.SecretString); got != r.want {
+			t.Errorf("%s: got %q, want %q", r.name, got, r.want)
+		}
+		if n := stub.calls(); n != r.wantCalls {
+			t.Errorf("%s: calls = %d, want %d", r.name, n, r.wantCalls)
+		}
+	}
+}
+
+func TestCachingClientMaxEntries(t *testing.T) {
+	tests := []struct {
+		name          string
+		maxEntries    int
+		reads         []string
+		wantCalls     int
+		wantEvictions int64
+	}{
+		{"under the limit", 2, []string{"a", "b", "a", "b"}, 2, 0},
+		{"least recently used is evicted", 2, []string{"a", "b", "c", "a"}, 4, 2},
+		{"recent read keeps an entry", 2, []string{"a", "b", "a", "c", "a"}, 3, 1},
+		{"single entry", 1, []string{"a", "a", "b", "a"}, 3, 2},
+		{"unlimited", 0, []string{"a", "b", "c", "a", "b", "c"}, 3, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := newStringStub(map[string]string{"a": "1", "b": "2", "c": "3"})
+			c, _ := newTestCache(stub, CacheOptions{TTL: time.Minute, MaxEntries: tt.maxEntries})
+
+			for _, name := range tt.reads {
+				if _, err := c.GetSecretValue(getInput(name)); err != nil {
+					t.Fatal(err)
+				}
+				if tt.maxEntries > 0 && len(c.entries) > tt.maxEntries {
+					t.Fatalf("%d entries cached, want at most %d", len(c.entries), tt.maxEntries)
+				}
+			}
+			if n := stub.calls(); n != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", n, tt.wantCalls)
+			}
+			if got := c.Stats().Evictions; got != tt.wantEvictions {
+				t.Errorf("Evictions = %d, want %d", got, tt.wantEvictions)
+			}
+		})
+	}
+}
+
+func TestCachingClientStats(t *testing.T) {
+	type step struct {
+		read       string
+		advance    time.Duration
+		invalidate string
+	}
+	tests := []struct {
+		name  string
+		steps []step
+		want  CacheStats
+	}{
+		{"empty", nil, CacheStats{}},
+		{"miss then hits", []step{{read: "a"}, {read: "a"}, {read: "a"}}, CacheStats{Hits: 2, Misses: 1}},
+		{"errors are misses", []step{{read: "missing"}, {read: "missing"}}, CacheStats{Misses: 2}},
+		{"expiry evicts", []step{{read: "a", advance: time.Minute}, {read: "a"}}, CacheStats{Misses: 2, Evictions: 1}},
+		{"invalidate evicts", []step{{read: "a"}, {read: "b"}, {invalidate: "a"}, {read: "a"}, {read: "b"}}, CacheStats{Hits: 1, Misses: 3, Evictions: 1}},
+		{"invalidate unknown", []step{{read: "a"}, {invalidate: "b"}}, CacheStats{Misses: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, clock := newTestCache(newStringStub(map[string]string{"a": "1", "b": "2"}), CacheOptions{TTL: time.Minute})
+			for _, s := range tt.steps {
+				if s.invalidate != "" {
+					c.Invalidate(s.invalidate)
+					continue
+				}
+				c.GetSecretValue(getInput(s.read))
+				clock.advance(s.advance)
+			}
+			if got := c.Stats(); got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCachingClientStatsInvalidateAll(t *testing.T) {
+	c, _ := newTestCache(newStringStub(map[string]string{"a": "1", "b": "2"}), CacheOptions{TTL: time.Minute})
+	c.GetSecretValue(getInput("a"))
+	c.GetSecretValue(getInput("b"))
+	c.InvalidateAll()
+
+	if got, want := c.Stats(), (CacheStats{Misses: 2, Evictions: 2}); got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestCachingClientServeStaleOnError(t *testing.T) {
+	throttled := awserr.New("ThrottlingException", "rate exceeded", nil)
+	tests := []struct {
+		name      string
+		opts      CacheOptions
+		advance   time.Duration
+		failWith  error
+		wantStale bool
+	}{
+		{"disabled", CacheOptions{TTL: time.Minute}, time.Minute, throttled, false},
+		{"enabled", CacheOptions{TTL: time.Minute, ServeStaleOnError: true}, time.Minute, throttled, true},
+		{"no max stale", CacheOptions{TTL: time.Minute, ServeStaleOnError: true}, 24 * time.Hour, throttled, true},
+		{"within max stale", CacheOptions{TTL: time.Minute, ServeStaleOnError: true, MaxStale: time.Hour}, time.Hour, throttled, true},
+		{"beyond max stale", CacheOptions{TTL: time.Minute, ServeStaleOnError: true, MaxStale: time.Hour}, time.Hour + time.Minute, throttled, false},
+		{"deleted secret", CacheOptions{TTL: time.Minute, ServeStaleOnError: true}, time.Minute, awserr.New(secretsmanager.ErrCodeResourceNotFoundException, "not found", nil), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var failing error
+			stub := &stubClient{get: func(input *secretsmanager.GetSecretValueInput) (*secretsmanager.GetSecretValueOutput, error) {
+				if failing != nil {
+					return nil, failing
+				}
+				return &secretsmanager.GetSecretValueOutput{Name: input.SecretId, SecretString: aws.String("1")}, nil
+			}}
+			c, clock := newTestCache(stub, tt.opts)
+
+			if _, err := c.GetSecretValue(getInput("a")); err != nil {
+				t.Fatal(err)
+			}
+			clock.advance(tt.advance)
+			failing = tt.failWith
+
+			output, err := c.GetSecretValue(getInput("a"))
+			if !tt.wantStale {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := aws.StringValue(output.SecretString); got != "1" {
+				t.Errorf("got %q, want the stale value", got)
+			}
+			if got := c.Stats().StaleHits; got != 1 {
+				t.Errorf("StaleHits = %d, want 1", got)
+			}
+
+			// The stale entry is kept, so recovery is picked up once the
+			// refresh is retried.
+			failing = nil
+			clock.advance(time.Minute)
+			if _, err := c.GetSecretValue(getInput("a")); err != nil {
+				t.Fatal(err)
+			}
+			if n := stub.calls(); n != 3 {
+				t.Errorf("calls = %d, want 3", n)
+			}
+		})
+	}
+}
+
+func TestCachingClientServeStaleRetryInterval(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      CacheOptions
+		wantRetry time.Duration
+	}{
+		{"defaults to TTL", CacheOptions{TTL: time.Minute, ServeStaleOnError: true}, time.Minute},
+		{"explicit interval", CacheOptions{TTL: time.Minute, ServeStaleOnError: true, StaleRetryInterval: 10 * time.Second}, 10 * time.Second},
+		{"capped by max stale", CacheOptions{TTL: time.Minute, ServeStaleOnError: true, MaxStale: 30 * time.Second}, 30 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &stubClient{get: func(input *secretsmanager.GetSecretValueInput) (*secretsmanager.GetSecretValueOutput, error) {
+				return &secretsmanager.GetSecretValueOutput{Name: input.SecretId, SecretString: aws.String("1")}, nil
+			}}
+			c, clock := newTestCache(stub, tt.opts)
+			if _, err := c.GetSecretValue(getInput("a")); err != nil {
+				t.Fatal(err)
+			}
+
+			stub.get = func(*secretsmanager.GetSecretValueInput) (*secretsmanager.GetSecretValueOutput, error) {
+				return nil, awserr.New("ThrottlingException", "rate exceeded", nil)
+			}
+			clock.advance(time.Minute)
+			if _, err := c.GetSecretValue(getInput("a")); err != nil {
+				t.Fatal(err)
+			}
+
+			// Until the retry is due, the stale value is served without
+			// calling the failing API.
+			clock.advance(tt.wantRetry - time.Nanosecond)
+			if _, err := c.GetSecretValue(getInput("a")); err != nil {
+				t.Fatal(err)
+			}
+			if n := stub.calls(); n != 2 {
+				t.Errorf("calls before the retry = %d, want 2", n)
+			}
+			if got := c.Stats().StaleHits; got != 2 {
+				t.Errorf("StaleHits = %d, want 2", got)
+			}
+
+			clock.advance(time.Nanosecond)
+			c.GetSecretValue(getInput("a"))
+			if n := stub.calls(); n != 3 {
+				t.Errorf("calls after the retry = %d, want 3", n)
+			}
+		})
+	}
+}
+
+func TestCachingClientServeStaleMarksFetchEvent(t *testing.T) {
+	events := captureEvents(t)
+	failing := false
+	stub := &stubClient{get: func(input *secretsmanager.GetSecretValueInput) (*secretsmanager.GetSecretValueOutput, error) {
+		if failing {
+			return nil, awserr.New("ThrottlingException", "rate exceeded", nil)
+		}
+		return &secretsmanager.GetSecretValueOutput{Name: input.SecretId, SecretString: aws.String("1")}, nil
+	}}
+	c, clock := newTestCache(stub, CacheOptions{TTL: time.Minute, ServeStaleOnError: true})
+	useDefaultAPI(t, c)
+
+	if _, err := GetStringSecret(nil, "a"); err != nil {
+		t.Fatal(err)
+	}
+	clock.advance(time.Minute)
+	failing = true
+	if _, err := GetStringSecret(nil, "a"); err != nil {
+		t.Fatal(err)
+	}
+
+	got := events()
+	if len(got) != 2 {
+		t.Fatalf("got %d events, want 2", len(got))
+	}
+	if got[0].Stale || !got[1].Stale || !got[1].CacheHit {
+		t.Errorf("events = %+v, want only the second to be a stale cache hit", got)
+	}
+}
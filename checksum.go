@@ -0,0 +1,36 @@
+package awssecret
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+// GetSecretChecksum retrieves the named secret from AWS Secrets Manager and
+// returns the hex-encoded SHA-256 of its raw value (the SecretString if
+// present, otherwise the SecretBinary). Comparing checksums detects a
+// changed value without keeping the previous value around.
+//
+func GetSecretChecksum(sess *session.Session, secretName string) (checksum string, err error) {
+	return GetSecretChecksumWithContext(context.Background(), sess, secretName)
+}
+
+// GetSecretChecksumWithContext is the same as GetSecretChecksum, but uses
+// the supplied context for the underlying AWS request.
+//
+func GetSecretChecksumWithContext(ctx context.Context, sess *session.Session, secretName string) (checksum string, err error) {
+	data, err := getSecretBytes(ctx, clientFor(sess), secretName)
+	if err != nil {
+		return "", err
+	}
+	return secretChecksum(data), nil
+}
+
+// secretChecksum returns the hex-encoded SHA-256 of data.
+//
+func secretChecksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
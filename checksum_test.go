@@ -0,0 +1,40 @@
+package awssecret
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/adlio/awssecret/awssecrettest"
+)
+
+func TestGetSecretChecksum(t *testing.T) {
+	fake := awssecrettest.NewFakeClient()
+	fake.SetString("empty", "")
+	fake.SetString("text", "hello")
+	fake.SetBinary("binary", []byte("hello"))
+	fake.SetString("changed", "hello!")
+	useDefaultAPI(t, fake)
+
+	tests := []struct {
+		secret  string
+		want    string
+		wantErr error
+	}{
+		{"text", "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", nil},
+		{"binary", "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", nil},
+		{"changed", "ce06092fb948d9ffac7d1a376e404b26b7575bcc11ee05a4615fef4fec3a308b", nil},
+		{"empty", "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", nil},
+		{"missing", "", ErrSecretNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.secret, func(t *testing.T) {
+			got, err := GetSecretChecksum(nil, tt.secret)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,386 @@
+package awssecret
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/pkg/errors"
+)
+
+// SecretsManagerAPI is the subset of the AWS Secrets Manager client used to
+// retrieve secret values. *secretsmanager.SecretsManager satisfies it, and
+// tests can supply a hand-rolled stub in its place.
+//
+type SecretsManagerAPI interface {
+	GetSecretValue(*secretsmanager.GetSecretValueInput) (*secretsmanager.GetSecretValueOutput, error)
+}
+
+// contextSecretsManagerAPI is implemented by clients (such as the real AWS
+// client) which can abort an in-flight request when its context is done.
+//
+type contextSecretsManagerAPI interface {
+	GetSecretValueWithContext(context.Context, *secretsmanager.GetSecretValueInput, ...request.Option) (*secretsmanager.GetSecretValueOutput, error)
+}
+
+// ClientOptions overrides parts of the AWS configuration used when building
+// a Secrets Manager client. Zero values leave the session's configuration
+// unchanged.
+//
+type ClientOptions struct {
+	// Endpoint overrides the Secrets Manager endpoint, e.g.
+	// http://localhost:4566 for LocalStack.
+	Endpoint string
+
+	// Region overrides the session's region. Setting it also pins the
+	// client to that region: by default, secrets given by an ARN are read
+	// from the region the ARN names.
+	Region string
+
+	// RoleARN, when set, is assumed via STS using the session's
+	// credentials, and the resulting temporary credentials are used to
+	// access Secrets Manager.
+	RoleARN string
+
+	// ExternalID is passed to STS when assuming RoleARN.
+	ExternalID string
+
+	// RoleSessionName names the STS session when assuming RoleARN. The
+	// SDK generates a name if it is empty.
+	RoleSessionName string
+
+	// Config, when set, is applied to the client's configuration before
+	// the fields above, which take precedence over it. It can be used to
+	// supply a Retryer (or MaxRetries) so the SDK's own retry and backoff
+	// logic is used for every request. Retries configured here happen
+	// inside each call to GetSecretValue, so wrapping the resulting client
+	// in a RetryingClient as well multiplies the number of attempts; use
+	// one or the other.
+	Config *aws.Config
+
+	// HTTPClient, when set, sends the client's requests, e.g. to route
+	// them through a proxy or VPC endpoint with custom TLS settings.
+	// Combine it with Endpoint to target a VPC endpoint by name.
+	HTTPClient *http.Client
+}
+
+// config returns the aws.Config overrides described by the options.
+//
+func (o ClientOptions) config(sess *session.Session) *aws.Config {
+	cfg := aws.NewConfig()
+	if o.Config != nil {
+		cfg = o.Config.Copy()
+	}
+	if o.Endpoint != "" {
+		cfg = cfg.WithEndpoint(o.Endpoint)
+	}
+	if o.Region != "" {
+		cfg = cfg.WithRegion(o.Region)
+	}
+	if o.HTTPClient != nil {
+		cfg = cfg.WithHTTPClient(o.HTTPClient)
+	}
+	if o.RoleARN != "" {
+		cfg = cfg.WithCredentials(stscreds.NewCredentials(sess, o.RoleARN, func(p *stscreds.AssumeRoleProvider) {
+			if o.ExternalID != "" {
+				p.ExternalID = aws.String(o.ExternalID)
+			}
+			if o.RoleSessionName != "" {
+				p.RoleSessionName = o.RoleSessionName
+			}
+		}))
+	}
+	return cfg
+}
+
+// NewSecretsManagerClient adapts a session to the SecretsManagerAPI
+// interface. If sess is nil, a default session is built from the
+// environment and shared config.
+//
+func NewSecretsManagerClient(sess *session.Session) (client SecretsManagerAPI, err error) {
+	return NewSecretsManagerClientWithOptions(sess, ClientOptions{})
+}
+
+// NewSecretsManagerClientWithOptions is the same as NewSecretsManagerClient,
+// but applies the supplied overrides to the client's configuration. As with
+// NewSecretsManagerClient, a nil session still falls back to the environment
+// and shared config, with the overrides applied on top.
+//
+func NewSecretsManagerClientWithOptions(sess *session.Session, opts ClientOptions) (client SecretsManagerAPI, err error) {
+	return newService(sess, opts)
+}
+
+// newService builds the AWS Secrets Manager client, creating a default
+// session if one wasn't passed in. Secrets given by an ARN are read from
+// the region the ARN names, unless opts.Region pins the client to a region.
+//
+func newService(sess *session.Session, opts ClientOptions) (svc *arnRegionClient, err error) {
+	sess, err = sessionOrDefault(sess)
+	if err != nil {
+		return nil, err
+	}
+
+	return newARNRegionClient(sess, opts.config(sess), opts.Region != ""), nil
+}
+
+// newSession is session.NewSessionWithOptions, replaceable in tests.
+var newSession = session.NewSessionWithOptions
+
+var (
+	defaultSessionMu sync.Mutex
+	defaultSession   *session.Session
+	requireSession   bool
+)
+
+// sessionOrDefault returns sess, or if sess is nil, a default session built
+// from the environment and shared config. The default session is built
+// once and shared by every caller; a failure to build it isn't remembered,
+// so the next call tries again. After SetRequireSession(true), a nil
+// session is an error instead.
+//
+func sessionOrDefault(sess *session.Session) (*session.Session, error) {
+	if sess != nil {
+		return sess, nil
+	}
+
+	defaultSessionMu.Lock()
+	defer defaultSessionMu.Unlock()
+
+	if requireSession {
+		return nil, ErrNoSession
+	}
+	if defaultSession == nil {
+		//Create a Secrets Manager client if one wasn't passed in
+		s, err := newSession(
+			session.Options{
+				SharedConfigState: session.SharedConfigEnable,
+			},
+		)
+		if err != nil {
+			return nil, err
+		}
+		defaultSession = s
+	}
+
+	return defaultSession, nil
+}
+
+// SetRequireSession controls whether a nil session is an error. By default
+// a nil session falls back to one built from the environment and shared
+// config, which can silently pick up unexpected credentials. When require
+// is true, every call made without a session fails with ErrNoSession
+// instead, forcing credentials to be configured explicitly.
+//
+func SetRequireSession(require bool) {
+	defaultSessionMu.Lock()
+	requireSession = require
+	defaultSessionMu.Unlock()
+
+	ResetDefaultSession()
+}
+
+// ResetDefaultSession discards the shared default session, and the Client
+// built from it, so that the next call without a session builds them
+// afresh from the environment. It is intended for tests which change the
+// environment or shared config between cases.
+//
+func ResetDefaultSession() {
+	defaultSessionMu.Lock()
+	defaultSession = nil
+	defaultSessionMu.Unlock()
+
+	defaultClientMu.Lock()
+	defaultClient = nil
+	defaultClientMu.Unlock()
+}
+
+// GetStringSecretWithClient retrieves the named secret using the supplied
+// client and returns it in its raw form.
+//
+func GetStringSecretWithClient(client SecretsManagerAPI, secretName string) (secret string, err error) {
+	return GetStringSecretWithClientContext(context.Background(), client, secretName)
+}
+
+// GetStringSecretWithClientContext is the same as GetStringSecretWithClient,
+// but uses the supplied context for the underlying request.
+//
+func GetStringSecretWithClientContext(ctx context.Context, client SecretsManagerAPI, secretName string) (secret string, err error) {
+	return getStringSecret(ctx, client, newGetSecretValueInput(secretName, "", ""))
+}
+
+// getSecretValue performs the GetSecretValue request using the supplied
+// client and reports it to the package Logger. AWS errors with a
+// corresponding sentinel (e.g. ErrSecretNotFound) are returned so that they
+// match it via errors.Is, and a DecryptionFailure is returned as a
+// *DecryptionError naming the KMS key involved.
+//
+func getSecretValue(ctx context.Context, client SecretsManagerAPI, input *secretsmanager.GetSecretValueInput) (result *secretsmanager.GetSecretValueOutput, err error) {
+
+	// In this sample we only handle the specific exceptions for the 'GetSecretValue' API.
+	// See https://docs.aws.amazon.com/secretsmanager/latest/apireference/API_GetSecretValue.html
+
+	err = validateSecretID(aws.StringValue(input.SecretId))
+	if err != nil {
+		return nil, err
+	}
+
+	event := &FetchEvent{SecretName: aws.StringValue(input.SecretId)}
+	start := time.Now()
+	result, err = callGetSecretValue(context.WithValue(ctx, fetchEventKey{}, event), client, input)
+	event.Duration = time.Since(start)
+	event.Err = err
+	currentLogger().LogFetch(*event)
+
+	if err != nil {
+		code := "Unknown error"
+		if aerr, ok := err.(awserr.Error); ok {
+			code = aerr.Code()
+		}
+		err = enrichDecryptionFailure(ctx, client, aws.StringValue(input.SecretId), mapAWSError(aws.StringValue(input.SecretId), err))
+		return nil, errors.Wrapf(err, "Failed to get secret %q from AWS Secrets Manager: %s", aws.StringValue(input.SecretId), code)
+	}
+
+	return result, nil
+}
+
+// callGetSecretValue calls GetSecretValueWithContext when the client
+// supports it, and otherwise checks the context before calling
+// GetSecretValue.
+//
+func callGetSecretValue(ctx context.Context, client SecretsManagerAPI, input *secretsmanager.GetSecretValueInput, opts ...request.Option) (*secretsmanager.GetSecretValueOutput, error) {
+	if c, ok := client.(contextSecretsManagerAPI); ok {
+		return c.GetSecretValueWithContext(ctx, input, opts...)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return client.GetSecretValue(input)
+}
+
+// Client is a long-lived handle for reading secrets, which builds its
+// Secrets Manager client once and reuses it for every call. It is safe for
+// concurrent use.
+//
+type Client struct {
+	api    SecretsManagerAPI
+	err    error
+	prefix string
+}
+
+var (
+	defaultClientMu sync.Mutex
+	defaultClient   *Client
+)
+
+// NewClient returns a Client which reads secrets using the supplied
+// session. If sess is nil, a default session is built from the environment
+// and shared config; if that fails, every method returns the error.
+// Secrets given by an ARN are read from the region the ARN names, rather
+// than the session's region.
+//
+func NewClient(sess *session.Session) *Client {
+	svc, err := newService(sess, ClientOptions{})
+	if err != nil {
+		return &Client{err: err}
+	}
+	return &Client{api: svc}
+}
+
+// NewClientWithAPI returns a Client which reads secrets using the supplied
+// SecretsManagerAPI, such as a CachingClient, a RetryingClient or a stub.
+//
+func NewClientWithAPI(api SecretsManagerAPI) *Client {
+	return &Client{api: api}
+}
+
+// clientFor returns a Client for sess. The package-level functions share a
+// single, lazily-created Client when no session is supplied, which is
+// rebuilt if creating it failed.
+//
+func clientFor(sess *session.Session) *Client {
+	if sess != nil {
+		return NewClient(sess)
+	}
+	defaultClientMu.Lock()
+	defer defaultClientMu.Unlock()
+	if defaultClient == nil || defaultClient.err != nil {
+		defaultClient = NewClient(nil)
+	}
+	return defaultClient
+}
+
+// WithPrefix returns a copy of the Client which prepends prefix to every
+// secret name it is given, e.g. "myapp/prod/" so that callers can pass
+// short names. Secrets given by ARN are used as-is.
+//
+func (c *Client) WithPrefix(prefix string) *Client {
+	prefixed := *c
+	prefixed.prefix = prefix
+	return &prefixed
+}
+
+// secretID returns the ID to request for secretName, applying the
+// Client's prefix to friendly names.
+//
+func (c *Client) secretID(secretName string) string {
+	if strings.HasPrefix(secretName, "arn:") {
+		return secretName
+	}
+	return c.prefix + secretName
+}
+
+// GetString retrieves the named secret and returns it in its raw form.
+//
+func (c *Client) GetString(secretName string) (secret string, err error) {
+	return c.GetStringWithContext(context.Background(), secretName)
+}
+
+// GetStringWithContext is the same as GetString, but uses the supplied
+// context for the underlying request.
+//
+func (c *Client) GetStringWithContext(ctx context.Context, secretName string) (secret string, err error) {
+	if c.err != nil {
+		return "", c.err
+	}
+	return getStringSecret(ctx, c.api, newGetSecretValueInput(c.secretID(secretName), "", ""))
+}
+
+// GetCredential retrieves the named secret and JSON-decodes it into a
+// Credential.
+//
+func (c *Client) GetCredential(secretName string) (cred *Credential, err error) {
+	return c.GetCredentialWithContext(context.Background(), secretName)
+}
+
+// GetCredentialWithContext is the same as GetCredential, but uses the
+// supplied context for the underlying request.
+//
+func (c *Client) GetCredentialWithContext(ctx context.Context, secretName string) (cred *Credential, err error) {
+	return getJSONSecretAs[Credential](ctx, c, secretName, JSONOptions{}, "Couldn't build credential")
+}
+
+// GetPostgresDSN retrieves the named secret and converts it into a
+// Postgres-compatible DSN string, as GetPostgresDSNSecret does.
+//
+func (c *Client) GetPostgresDSN(secretName string) (dsnStr string, err error) {
+	return c.GetPostgresDSNWithContext(context.Background(), secretName)
+}
+
+// GetPostgresDSNWithContext is the same as GetPostgresDSN, but uses the
+// supplied context for the underlying request.
+//
+func (c *Client) GetPostgresDSNWithContext(ctx context.Context, secretName string) (dsnStr string, err error) {
+	str, err := c.GetStringWithContext(ctx, secretName)
+	if err != nil {
+		return "", errors.Wrapf(err, "Couldn't build DSN. Failed to retrieve secret")
+	}
+	return postgresDSN(str)
+}
@@ -0,0 +1,477 @@
+package awssecret
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+func TestGetStringSecretWithClient(t *testing.T) {
+	stub := newStringStub(map[string]string{"db": "hunter2"})
+
+	tests := []struct {
+		secret  string
+		want    string
+		wantErr bool
+	}{
+		{"db", "hunter2", false},
+		{"missing", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.secret, func(t *testing.T) {
+			got, err := GetStringSecretWithClient(stub, tt.secret)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			input := stub.lastInput()
+			if aws.StringValue(input.SecretId) != tt.secret || aws.StringValue(input.VersionStage) != DefaultVersionStage {
+				t.Errorf("input = %v", input)
+			}
+		})
+	}
+}
+
+func TestGetStringSecretWithClientWrapsErrors(t *testing.T) {
+	cause := errors.New("connection reset")
+	_, err := GetStringSecretWithClient(newErrorStub(cause), "db")
+	if !errors.Is(err, cause) {
+		t.Errorf("err = %v, want it to wrap %v", err, cause)
+	}
+}
+
+func TestGetStringSecretWithClientContextSkipsCanceledRequests(t *testing.T) {
+	stub := newStringStub(map[string]string{"db": "hunter2"})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := GetStringSecretWithClientContext(ctx, stub, "db"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if n := stub.calls(); n != 0 {
+		t.Errorf("GetSecretValue calls = %d, want 0", n)
+	}
+}
+
+func TestClientOptionsEndpoint(t *testing.T) {
+	url, requests := newSecretsManagerServer(t, func(req smRequest) (int, interface{}) {
+		if req.Body["SecretId"] != "db" {
+			return http.StatusBadRequest, map[string]string{"__type": "ResourceNotFoundException", "message": "not found"}
+		}
+		return http.StatusOK, map[string]string{"Name": "db", "SecretString": "hunter2"}
+	})
+
+	client, err := NewSecretsManagerClientWithOptions(testSession(t), ClientOptions{Endpoint: url})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		secret  string
+		want    string
+		wantErr error
+	}{
+		{"db", "hunter2", nil},
+		{"missing", "", ErrSecretNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.secret, func(t *testing.T) {
+			got, err := GetStringSecretWithClient(client, tt.secret)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if req := <-requests; req.Operation != "GetSecretValue" {
+				t.Errorf("operation = %q, want GetSecretValue", req.Operation)
+			}
+		})
+	}
+}
+
+func TestClientOptionsRegion(t *testing.T) {
+	tests := []struct {
+		region string
+		want   string
+	}{
+		{"", "us-east-1"},
+		{"eu-west-1", "eu-west-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			client, err := NewSecretsManagerClientWithOptions(testSession(t), ClientOptions{Region: tt.region})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := aws.StringValue(client.(*arnRegionClient).serviceFor("db").Config.Region); got != tt.want {
+				t.Errorf("region = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// failingTransport is an http.RoundTripper which fails every request.
+type failingTransport struct{}
+
+func (failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errors.New("sent through the session's HTTP client")
+}
+
+func TestClientOptionsHTTPClient(t *testing.T) {
+	sess := testSession(t).Copy(aws.NewConfig().WithHTTPClient(&http.Client{Transport: failingTransport{}}))
+
+	tests := []struct {
+		name     string
+		endpoint string
+		secretID string
+		wantHost string
+	}{
+		{"default endpoint", "", "db", "secretsmanager.us-east-1.amazonaws.com"},
+		{"VPC endpoint", "https://vpce-0123-abcd.secretsmanager.us-east-1.vpce.amazonaws.com", "db", "vpce-0123-abcd.secretsmanager.us-east-1.vpce.amazonaws.com"},
+		{"ARN in another region", "", euARN, "secretsmanager.eu-west-1.amazonaws.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &hostRecorder{}
+			svc, err := NewSecretsManagerClientWithOptions(sess, ClientOptions{
+				Endpoint:   tt.endpoint,
+				HTTPClient: &http.Client{Transport: recorder},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := GetStringSecretWithClient(svc, tt.secretID)
+			if err != nil || got != "hunter2" {
+				t.Fatalf("got %q, %v", got, err)
+			}
+			if host := recorder.last(); host != tt.wantHost {
+				t.Errorf("request sent to %q, want %q", host, tt.wantHost)
+			}
+		})
+	}
+}
+
+func TestClientMethods(t *testing.T) {
+	stub := newStringStub(map[string]string{
+		"token": "hunter2",
+		"db":    `{"host":"db.local","port":5432,"dbname":"app","username":"admin","password":"pw"}`,
+	})
+	c := NewClientWithAPI(stub)
+
+	if got, err := c.GetString("token"); err != nil || got != "hunter2" {
+		t.Errorf("GetString = %q, %v", got, err)
+	}
+	if cred, err := c.GetCredential("db"); err != nil || cred.Username != "admin" || cred.Port != 5432 {
+		t.Errorf("GetCredential = %+v, %v", cred, err)
+	}
+	if got, err := c.GetPostgresDSN("db"); err != nil || got != "host=db.local port=5432 dbname=app user=admin password=pw " {
+		t.Errorf("GetPostgresDSN = %q, %v", got, err)
+	}
+	if n := stub.calls(); n != 3 {
+		t.Errorf("calls = %d, want 3", n)
+	}
+}
+
+// countingRetryer is the SDK's default retryer, counting the retries it
+// schedules.
+type countingRetryer struct {
+	client.DefaultRetryer
+	retries int32
+}
+
+func (r *countingRetryer) RetryRules(req *request.Request) time.Duration {
+	atomic.AddInt32(&r.retries, 1)
+	return r.DefaultRetryer.RetryRules(req)
+}
+
+func TestClientOptionsConfigRetryer(t *testing.T) {
+	url, requests := newSecretsManagerServer(t, func(req smRequest) (int, interface{}) {
+		return http.StatusInternalServerError, map[string]string{"__type": "InternalServiceError", "message": "try again"}
+	})
+
+	tests := []struct {
+		name       string
+		maxRetries int
+	}{
+		{"no retries", 0},
+		{"two retries", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			retryer := &countingRetryer{DefaultRetryer: client.DefaultRetryer{
+				NumMaxRetries: tt.maxRetries,
+				MinRetryDelay: time.Millisecond,
+				MaxRetryDelay: time.Millisecond,
+			}}
+			svc, err := NewSecretsManagerClientWithOptions(testSession(t), ClientOptions{
+				Endpoint: url,
+				Config:   request.WithRetryer(aws.NewConfig(), retryer),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := GetStringSecretWithClient(svc, "db"); err == nil {
+				t.Fatal("expected an error")
+			}
+			if n := len(requests); n != tt.maxRetries+1 {
+				t.Errorf("requests = %d, want %d", n, tt.maxRetries+1)
+			}
+			if n := atomic.LoadInt32(&retryer.retries); int(n) != tt.maxRetries {
+				t.Errorf("retries = %d, want %d", n, tt.maxRetries)
+			}
+			for len(requests) > 0 {
+				<-requests
+			}
+		})
+	}
+}
+
+func TestClientOptionsTakePrecedenceOverConfig(t *testing.T) {
+	url, requests := newSecretsManagerServer(t, func(req smRequest) (int, interface{}) {
+		return http.StatusOK, map[string]string{"Name": "db", "SecretString": "hunter2"}
+	})
+
+	svc, err := NewSecretsManagerClientWithOptions(testSession(t), ClientOptions{
+		Endpoint: url,
+		Config:   aws.NewConfig().WithEndpoint("http://127.0.0.1:1").WithMaxRetries(0),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := GetStringSecretWithClient(svc, "db")
+	if err != nil || got != "hunter2" {
+		t.Fatalf("got %q, %v; want hunter2", got, err)
+	}
+	if n := len(requests); n != 1 {
+		t.Errorf("requests = %d, want 1", n)
+	}
+}
+
+// isolateDefaultSession points the shared config at files which don't
+// exist and discards the default session and Client for the rest of the
+// test, so that sessions built from the environment are predictable.
+func isolateDefaultSession(t *testing.T) {
+	t.Helper()
+	t.Setenv("AWS_REGION", "us-east-1")
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(t.TempDir(), "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(t.TempDir(), "credentials"))
+	ResetDefaultSession()
+	t.Cleanup(ResetDefaultSession)
+}
+
+func TestDefaultSessionIsShared(t *testing.T) {
+	isolateDefaultSession(t)
+
+	const callers = 20
+	sessions := make([]*session.Session, callers)
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			s, err := sessionOrDefault(nil)
+			if err != nil {
+				t.Error(err)
+			}
+			sessions[i] = s
+		}(i)
+	}
+	wg.Wait()
+
+	for i, s := range sessions {
+		if s == nil || s != sessions[0] {
+			t.Errorf("caller %d got a different session", i)
+		}
+	}
+	if first, second := clientFor(nil), clientFor(nil); first != second {
+		t.Error("default Client was rebuilt")
+	}
+
+	ResetDefaultSession()
+	if s, err := sessionOrDefault(nil); err != nil || s == sessions[0] {
+		t.Errorf("after ResetDefaultSession got the old session (err = %v)", err)
+	}
+}
+
+func TestSessionOrDefaultExplicit(t *testing.T) {
+	isolateDefaultSession(t)
+
+	explicit := testSession(t)
+	s, err := sessionOrDefault(explicit)
+	if err != nil || s != explicit {
+		t.Errorf("got %p, %v; want the explicit session", s, err)
+	}
+	if defaultSession != nil {
+		t.Error("an explicit session shouldn't build the default")
+	}
+}
+
+func TestPackageFunctionsUseDefaultClient(t *testing.T) {
+	stub := newStringStub(map[string]string{"db": "hunter2"})
+	useDefaultAPI(t, stub)
+
+	for i := 0; i < 2; i++ {
+		if got, err := GetStringSecret(nil, "db"); err != nil || got != "hunter2" {
+			t.Fatalf("GetStringSecret = %q, %v", got, err)
+		}
+	}
+	if n := stub.calls(); n != 2 {
+		t.Errorf("calls = %d, want 2", n)
+	}
+}
+
+// fakeSessions replaces newSession for the rest of the test, counting how
+// many sessions are built. Each build fails with err if it's non-nil.
+func fakeSessions(t *testing.T, err error) *int32 {
+	t.Helper()
+	var builds int32
+	previous := newSession
+	newSession = func(session.Options) (*session.Session, error) {
+		atomic.AddInt32(&builds, 1)
+		if err != nil {
+			return nil, err
+		}
+		return session.NewSession(aws.NewConfig().
+			WithRegion("us-east-1").
+			WithCredentials(credentials.AnonymousCredentials))
+	}
+	ResetDefaultSession()
+	t.Cleanup(func() {
+		newSession = previous
+		ResetDefaultSession()
+	})
+	return &builds
+}
+
+func TestDefaultSessionBuildFailureIsRetried(t *testing.T) {
+	builds := fakeSessions(t, errors.New("no shared config"))
+
+	for i := 0; i < 2; i++ {
+		if _, err := sessionOrDefault(nil); err == nil {
+			t.Fatal("expected an error")
+		}
+	}
+	if n := atomic.LoadInt32(builds); n != 2 {
+		t.Errorf("sessions built = %d, want 2", n)
+	}
+}
+
+func TestDefaultClientIsReused(t *testing.T) {
+	builds := fakeSessions(t, nil)
+
+	first := clientFor(nil)
+	second := clientFor(nil)
+	if first != second {
+		t.Error("default Client was rebuilt")
+	}
+	if n := atomic.LoadInt32(builds); n != 1 {
+		t.Errorf("sessions built = %d, want 1", n)
+	}
+}
+
+func TestDefaultClientIsRebuiltAfterFailure(t *testing.T) {
+	fakeSessions(t, errors.New("no shared config"))
+	if c := clientFor(nil); c.err == nil {
+		t.Fatal("expected the default Client to fail")
+	}
+
+	// Fix the environment without resetting the default session or Client.
+	newSession = func(session.Options) (*session.Session, error) { return testSession(t), nil }
+	if c := clientFor(nil); c.err != nil {
+		t.Errorf("default Client wasn't rebuilt: %v", c.err)
+	}
+}
+
+func TestSetRequireSession(t *testing.T) {
+	isolateDefaultSession(t)
+	SetRequireSession(true)
+	t.Cleanup(func() { SetRequireSession(false) })
+
+	if _, err := sessionOrDefault(nil); err != ErrNoSession {
+		t.Errorf("nil session: err = %v, want ErrNoSession", err)
+	}
+	explicit := testSession(t)
+	if s, err := sessionOrDefault(explicit); err != nil || s != explicit {
+		t.Errorf("explicit session: got %p, %v", s, err)
+	}
+
+	SetRequireSession(false)
+	if _, err := sessionOrDefault(nil); err != nil {
+		t.Errorf("after SetRequireSession(false): err = %v", err)
+	}
+}
+
+func TestClientWithPrefix(t *testing.T) {
+	const arn = "arn:aws:secretsmanager:us-east-1:123456789012:secret:token-AbCdEf"
+	stub := newStringStub(map[string]string{
+		"myapp/prod/token": "hunter2",
+		"myapp/prod/db":    `{"host":"db.local","username":"admin","password":"pw"}`,
+		arn:                "by-arn",
+	})
+	base := NewClientWithAPI(stub)
+	c := base.WithPrefix("myapp/prod/")
+
+	tests := []struct {
+		name   string
+		call   func() error
+		wantID string
+	}{
+		{"GetString", func() error { _, err := c.GetString("token"); return err }, "myapp/prod/token"},
+		{"GetCredential", func() error { _, err := c.GetCredential("db"); return err }, "myapp/prod/db"},
+		{"GetPostgresDSN", func() error { _, err := c.GetPostgresDSN("db"); return err }, "myapp/prod/db"},
+		{"ARN is unprefixed", func() error { _, err := c.GetString(arn); return err }, arn},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); err != nil {
+				t.Fatal(err)
+			}
+			if got := aws.StringValue(stub.lastInput().SecretId); got != tt.wantID {
+				t.Errorf("SecretId = %q, want %q", got, tt.wantID)
+			}
+		})
+	}
+
+	if _, err := base.GetString("token"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("original Client err = %v, want ErrSecretNotFound", err)
+	}
+}
+
+func TestClientConstructionError(t *testing.T) {
+	SetRequireSession(true)
+	t.Cleanup(func() { SetRequireSession(false) })
+
+	c := NewClient(nil)
+	calls := []struct {
+		name string
+		call func() error
+	}{
+		{"GetString", func() error { _, err := c.GetString("db"); return err }},
+		{"GetCredential", func() error { _, err := c.GetCredential("db"); return err }},
+		{"GetPostgresDSN", func() error { _, err := c.GetPostgresDSN("db"); return err }},
+	}
+	for _, tt := range calls {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); !errors.Is(err, ErrNoSession) {
+				t.Errorf("err = %v, want ErrNoSession", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package awssecret
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/pkg/errors"
+)
+
+// driverDSNBuilders maps database/sql driver names to the function which
+// builds a DSN that driver accepts.
+var driverDSNBuilders = map[string]func(str string) (string, error){
+	"postgres":  postgresDSN,
+	"pgx":       postgresDSN,
+	"mysql":     func(str string) (string, error) { return mysqlDSN(str, MySQLDSNOptions{}) },
+	"sqlserver": sqlServerDSN,
+	"mssql":     sqlServerDSN,
+	"godror":    oracleDSN,
+}
+
+// OpenDBOptions controls how OpenDBFromSecretWithOptions opens the
+// database.
+//
+type OpenDBOptions struct {
+	// Ping verifies the connection after opening it, closing the *sql.DB
+	// and returning an error if the ping fails.
+	Ping bool
+}
+
+// OpenDBFromSecret retrieves the named secret from AWS Secrets Manager,
+// builds a DSN for driverName from it (postgres, pgx, mysql, sqlserver,
+// mssql or godror) and passes it to sql.Open. The driver must already be
+// registered by importing its package.
+//
+func OpenDBFromSecret(sess *session.Session, secretName, driverName string) (db *sql.DB, err error) {
+	return OpenDBFromSecretWithOptionsContext(context.Background(), sess, secretName, driverName, OpenDBOptions{})
+}
+
+// OpenDBFromSecretWithContext is the same as OpenDBFromSecret, but uses the
+// supplied context for the underlying AWS request.
+//
+func OpenDBFromSecretWithContext(ctx context.Context, sess *session.Session, secretName, driverName string) (db *sql.DB, err error) {
+	return OpenDBFromSecretWithOptionsContext(ctx, sess, secretName, driverName, OpenDBOptions{})
+}
+
+// OpenDBFromSecretWithOptions is the same as OpenDBFromSecret, but opens the
+// database according to opts.
+//
+func OpenDBFromSecretWithOptions(sess *session.Session, secretName, driverName string, opts OpenDBOptions) (db *sql.DB, err error) {
+	return OpenDBFromSecretWithOptionsContext(context.Background(), sess, secretName, driverName, opts)
+}
+
+// OpenDBFromSecretWithOptionsContext is the same as
+// OpenDBFromSecretWithOptions, but uses the supplied context for the
+// underlying AWS request and the ping.
+//
+func OpenDBFromSecretWithOptionsContext(ctx context.Context, sess *session.Session, secretName, driverName string, opts OpenDBOptions) (db *sql.DB, err error) {
+	build, ok := driverDSNBuilders[driverName]
+	if !ok {
+		return nil, errors.Errorf("Couldn't open database. No DSN builder for driver %q", driverName)
+	}
+
+	str, err := GetStringSecretWithContext(ctx, sess, secretName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Couldn't open database. Failed to retrieve secret")
+	}
+
+	dsnStr, err := build(str)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Couldn't open database. Failed to build DSN")
+	}
+
+	db, err = sql.Open(driverName, dsnStr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Couldn't open database")
+	}
+
+	if opts.Ping {
+		err = db.PingContext(ctx)
+		if err != nil {
+			db.Close()
+			return nil, errors.Wrapf(err, "Couldn't open database. Ping failed")
+		}
+	}
+
+	return db, nil
+}
@@ -0,0 +1,157 @@
+package awssecret
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeDriver is a database/sql driver which records the DSN of each
+// connection opened, failing any whose DSN contains "unreachable".
+type fakeDriver struct {
+	mu   sync.Mutex
+	dsns []string
+}
+
+func (d *fakeDriver) Open(dsn string) (driver.Conn, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.dsns = append(d.dsns, dsn)
+	if strings.Contains(dsn, "unreachable") {
+		return nil, errors.New("connection refused")
+	}
+	return fakeConn{}, nil
+}
+
+func (d *fakeDriver) opened() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.dsns...)
+}
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (fakeConn) Close() error                              { return nil }
+func (fakeConn) Begin() (driver.Tx, error)                 { return nil, errors.New("not supported") }
+
+var (
+	fakeDriverOnce sync.Once
+	testDriver     = &fakeDriver{}
+)
+
+// useFakeDriver registers testDriver as a database/sql driver named
+// "awssecret-fake" whose DSNs are built by postgresDSN.
+func useFakeDriver(t *testing.T) *fakeDriver {
+	t.Helper()
+	fakeDriverOnce.Do(func() { sql.Register("awssecret-fake", testDriver) })
+	driverDSNBuilders["awssecret-fake"] = postgresDSN
+	t.Cleanup(func() { delete(driverDSNBuilders, "awssecret-fake") })
+	return testDriver
+}
+
+func TestDriverDSNBuilders(t *testing.T) {
+	const secret = `{"engine":"x","host":"db.local","port":1234,"username":"admin","password":"pw","dbname":"app"}`
+
+	tests := []struct {
+		driver string
+		want   string
+	}{
+		{"postgres", "host=db.local"},
+		{"pgx", "host=db.local"},
+		{"mysql", "admin:pw@tcp(db.local:1234)/app"},
+		{"sqlserver", "sqlserver://admin:pw@db.local:1234"},
+		{"mssql", "sqlserver://admin:pw@db.local:1234"},
+		{"godror", "admin/pw@db.local:1234/app"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.driver, func(t *testing.T) {
+			build, ok := driverDSNBuilders[tt.driver]
+			if !ok {
+				t.Fatalf("no DSN builder for %q", tt.driver)
+			}
+			got, err := build(secret)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("DSN = %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenDBFromSecretErrors(t *testing.T) {
+	useDefaultAPI(t, newStringStub(map[string]string{
+		"db":      `{"host":"db.local","username":"admin","password":"pw","dbname":"app"}`,
+		"invalid": `not json`,
+	}))
+
+	tests := []struct {
+		name    string
+		secret  string
+		driver  string
+		wantErr string
+	}{
+		{"unknown driver", "db", "sqlite3", "No DSN builder"},
+		{"missing secret", "missing", "godror", "Failed to retrieve secret"},
+		{"invalid secret", "invalid", "godror", "Failed to build DSN"},
+		{"unregistered driver", "db", "godror", "unknown driver"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := OpenDBFromSecret(nil, tt.secret, tt.driver)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOpenDBFromSecret(t *testing.T) {
+	useDefaultAPI(t, newStringStub(map[string]string{
+		"db":          `{"host":"db.local","username":"admin","password":"pw","dbname":"app"}`,
+		"unreachable": `{"host":"unreachable.local","username":"admin","password":"pw","dbname":"app"}`,
+	}))
+	fake := useFakeDriver(t)
+
+	tests := []struct {
+		name    string
+		secret  string
+		opts    OpenDBOptions
+		wantDSN string
+		wantErr bool
+	}{
+		{"no ping", "db", OpenDBOptions{}, "", false},
+		{"no ping unreachable", "unreachable", OpenDBOptions{}, "", false},
+		{"ping", "db", OpenDBOptions{Ping: true}, "host=db.local dbname=app user=admin password=pw ", false},
+		{"ping unreachable", "unreachable", OpenDBOptions{Ping: true}, "host=unreachable.local dbname=app user=admin password=pw ", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := len(fake.opened())
+
+			db, err := OpenDBFromSecretWithOptions(nil, tt.secret, "awssecret-fake", tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if db != nil {
+				defer db.Close()
+			}
+
+			opened := fake.opened()[before:]
+			if tt.wantDSN == "" {
+				if len(opened) != 0 {
+					t.Errorf("opened %q, want no connections", opened)
+				}
+				return
+			}
+			if len(opened) != 1 || opened[0] != tt.wantDSN {
+				t.Errorf("opened %q, want [%q]", opened, tt.wantDSN)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package awssecret
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/pkg/errors"
+)
+
+// DescribeSecretAPI is the subset of the AWS Secrets Manager client used to
+// read a secret's metadata.
+//
+type DescribeSecretAPI interface {
+	DescribeSecretWithContext(context.Context, *secretsmanager.DescribeSecretInput, ...request.Option) (*secretsmanager.DescribeSecretOutput, error)
+}
+
+// describer returns the Client's API as a DescribeSecretAPI, or an error if
+// the Client couldn't be built or its API can't describe secrets.
+//
+func (c *Client) describer() (DescribeSecretAPI, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	d, ok := c.api.(DescribeSecretAPI)
+	if !ok {
+		return nil, errors.New("Client's API doesn't implement DescribeSecretAPI")
+	}
+	return d, nil
+}
+
+// GetSecretKMSKey returns the ID of the KMS key the named secret is
+// encrypted with. It is empty when the secret uses the AWS managed key
+// aws/secretsmanager.
+//
+func GetSecretKMSKey(sess *session.Session, secretName string) (kmsKeyID string, err error) {
+	return GetSecretKMSKeyWithContext(context.Background(), sess, secretName)
+}
+
+// GetSecretKMSKeyWithContext is the same as GetSecretKMSKey, but uses the
+// supplied context for the underlying AWS request.
+//
+func GetSecretKMSKeyWithContext(ctx context.Context, sess *session.Session, secretName string) (kmsKeyID string, err error) {
+	svc, err := clientFor(sess).describer()
+	if err != nil {
+		return "", err
+	}
+	return GetSecretKMSKeyWithClientContext(ctx, svc, secretName)
+}
+
+// GetSecretKMSKeyWithClientContext is the same as GetSecretKMSKeyWithContext,
+// but uses the supplied client.
+//
+func GetSecretKMSKeyWithClientContext(ctx context.Context, client DescribeSecretAPI, secretName string) (kmsKeyID string, err error) {
+	result, err := describeSecret(ctx, client, secretName)
+	if err != nil {
+		return "", err
+	}
+	return aws.StringValue(result.KmsKeyId), nil
+}
+
+// GetSecretLastChanged returns when the named secret's value or metadata
+// was last changed, falling back to its creation date if it has never
+// changed. Comparing it with a previous result avoids refetching a value
+// which hasn't changed.
+//
+func GetSecretLastChanged(sess *session.Session, secretName string) (lastChanged time.Time, err error) {
+	return GetSecretLastChangedWithContext(context.Background(), sess, secretName)
+}
+
+// GetSecretLastChangedWithContext is the same as GetSecretLastChanged, but
+// uses the supplied context for the underlying AWS request.
+//
+func GetSecretLastChangedWithContext(ctx context.Context, sess *session.Session, secretName string) (lastChanged time.Time, err error) {
+	svc, err := clientFor(sess).describer()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return GetSecretLastChangedWithClientContext(ctx, svc, secretName)
+}
+
+// GetSecretLastChangedWithClientContext is the same as
+// GetSecretLastChangedWithContext, but uses the supplied client.
+//
+func GetSecretLastChangedWithClientContext(ctx context.Context, client DescribeSecretAPI, secretName string) (lastChanged time.Time, err error) {
+	result, err := describeSecret(ctx, client, secretName)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if result.LastChangedDate != nil {
+		return aws.TimeValue(result.LastChangedDate), nil
+	}
+	return aws.TimeValue(result.CreatedDate), nil
+}
+
+// describeSecret performs the DescribeSecret request using the supplied
+// client, wrapping errors the same way as getSecretValue.
+//
+func describeSecret(ctx context.Context, client DescribeSecretAPI, secretName string) (result *secretsmanager.DescribeSecretOutput, err error) {
+	err = validateSecretID(secretName)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err = client.DescribeSecretWithContext(ctx, &secretsmanager.DescribeSecretInput{
+		SecretId: aws.String(secretName),
+	})
+	if err != nil {
+		code := "Unknown error"
+		if aerr, ok := err.(awserr.Error); ok {
+			code = aerr.Code()
+		}
+		return nil, errors.Wrapf(mapAWSError(secretName, err), "Failed to describe secret %q in AWS Secrets Manager: %s", secretName, code)
+	}
+	return result, nil
+}
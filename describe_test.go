@@ -0,0 +1,153 @@
+package awssecret
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+)
+
+// describeStub is a hand-rolled DescribeSecretAPI answering from outputs.
+// Missing names get a ResourceNotFoundException.
+type describeStub struct {
+	outputs map[string]*secretsmanager.DescribeSecretOutput
+	calls   int
+}
+
+func (s *describeStub) DescribeSecretWithContext(ctx context.Context, input *secretsmanager.DescribeSecretInput, opts ...request.Option) (*secretsmanager.DescribeSecretOutput, error) {
+	s.calls++
+	output, ok := s.outputs[aws.StringValue(input.SecretId)]
+	if !ok {
+		return nil, awserr.New(secretsmanager.ErrCodeResourceNotFoundException, "not found", nil)
+	}
+	return output, nil
+}
+
+const testKMSKeyARN = "arn:aws:kms:us-east-1:123456789012:key/1234abcd-12ab-34cd-56ef-1234567890ab"
+
+func TestGetSecretKMSKey(t *testing.T) {
+	stub := &describeStub{outputs: map[string]*secretsmanager.DescribeSecretOutput{
+		"cmk":     {Name: aws.String("cmk"), KmsKeyId: aws.String(testKMSKeyARN)},
+		"managed": {Name: aws.String("managed")},
+	}}
+
+	tests := []struct {
+		secret  string
+		want    string
+		wantErr error
+	}{
+		{"cmk", testKMSKeyARN, nil},
+		{"managed", "", nil},
+		{"missing", "", ErrSecretNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.secret, func(t *testing.T) {
+			got, err := GetSecretKMSKeyWithClientContext(context.Background(), stub, tt.secret)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetSecretKMSKeyRejectsMalformedARN(t *testing.T) {
+	stub := &describeStub{}
+	_, err := GetSecretKMSKeyWithClientContext(context.Background(), stub, "arn:aws:secretsmanager:us-east-1")
+	if !errors.Is(err, ErrMalformedARN) {
+		t.Fatalf("err = %v, want ErrMalformedARN", err)
+	}
+	if stub.calls != 0 {
+		t.Errorf("DescribeSecret called %d times, want 0", stub.calls)
+	}
+}
+
+func TestGetSecretLastChanged(t *testing.T) {
+	created := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	changed := created.Add(72 * time.Hour)
+	stub := &describeStub{outputs: map[string]*secretsmanager.DescribeSecretOutput{
+		"changed":   {Name: aws.String("changed"), CreatedDate: aws.Time(created), LastChangedDate: aws.Time(changed)},
+		"unchanged": {Name: aws.String("unchanged"), CreatedDate: aws.Time(created)},
+		"undated":   {Name: aws.String("undated")},
+	}}
+
+	tests := []struct {
+		secret  string
+		want    time.Time
+		wantErr error
+	}{
+		{"changed", changed, nil},
+		{"unchanged", created, nil},
+		{"undated", time.Time{}, nil},
+		{"missing", time.Time{}, ErrSecretNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.secret, func(t *testing.T) {
+			got, err := GetSecretLastChangedWithClientContext(context.Background(), stub, tt.secret)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDescribeUsesDefaultClient(t *testing.T) {
+	created := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	describe := &describeStub{outputs: map[string]*secretsmanager.DescribeSecretOutput{
+		"db": {Name: aws.String("db"), KmsKeyId: aws.String(testKMSKeyARN), CreatedDate: aws.Time(created)},
+	}}
+	useDefaultAPI(t, struct {
+		*stubClient
+		*describeStub
+	}{newStringStub(map[string]string{"db": "hunter2"}), describe})
+
+	if got, err := GetSecretKMSKey(nil, "db"); err != nil || got != testKMSKeyARN {
+		t.Errorf("GetSecretKMSKey = %q, %v", got, err)
+	}
+	if got, err := GetSecretLastChanged(nil, "db"); err != nil || !got.Equal(created) {
+		t.Errorf("GetSecretLastChanged = %v, %v", got, err)
+	}
+	secret, err := GetSecretWithMetadataWithOptions(nil, "db", MetadataOptions{IncludeKMSKey: true})
+	if err != nil || secret.Value != "hunter2" || secret.KMSKeyID != testKMSKeyARN {
+		t.Errorf("GetSecretWithMetadataWithOptions = %+v, %v", secret, err)
+	}
+	if describe.calls != 3 {
+		t.Errorf("DescribeSecret calls = %d, want 3", describe.calls)
+	}
+}
+
+func TestDescribeRequiresDescribeSecretAPI(t *testing.T) {
+	useDefaultAPI(t, newStringStub(map[string]string{"db": "hunter2"}))
+
+	if _, err := GetSecretKMSKey(nil, "db"); err == nil {
+		t.Error("GetSecretKMSKey: expected an error from an API without DescribeSecret")
+	}
+	if _, err := GetSecretWithMetadataWithOptions(nil, "db", MetadataOptions{IncludeVersionStages: true}); err == nil {
+		t.Error("GetSecretWithMetadataWithOptions: expected an error from an API without DescribeSecret")
+	}
+}
+
+func TestDescribeRequiresSession(t *testing.T) {
+	SetRequireSession(true)
+	t.Cleanup(func() { SetRequireSession(false) })
+
+	if _, err := GetSecretKMSKey(nil, "db"); !errors.Is(err, ErrNoSession) {
+		t.Errorf("GetSecretKMSKey err = %v, want ErrNoSession", err)
+	}
+	if _, err := GetSecretLastChanged(nil, "db"); !errors.Is(err, ErrNoSession) {
+		t.Errorf("GetSecretLastChanged err = %v, want ErrNoSession", err)
+	}
+	if _, err := GetSecretWithMetadataWithOptions(nil, "db", MetadataOptions{}); !errors.Is(err, ErrNoSession) {
+		t.Errorf("GetSecretWithMetadataWithOptions err = %v, want ErrNoSession", err)
+	}
+}
@@ -0,0 +1,127 @@
+package awssecret
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/pkg/errors"
+)
+
+// GetDotenvSecret retrieves the named secret from AWS Secrets Manager and
+// parses it as a dotenv-style body of KEY=VALUE lines. Blank lines and
+// lines starting with # are ignored, as is an optional leading "export ".
+// Values may be single-quoted (taken literally) or double-quoted (where
+// \n, \t, \" and \\ are unescaped); unquoted values end at a " #" comment.
+// Later keys override earlier ones.
+//
+func GetDotenvSecret(sess *session.Session, secretName string) (env map[string]string, err error) {
+	return GetDotenvSecretWithContext(context.Background(), sess, secretName)
+}
+
+// GetDotenvSecretWithContext is the same as GetDotenvSecret, but uses the
+// supplied context for the underlying AWS request.
+//
+func GetDotenvSecretWithContext(ctx context.Context, sess *session.Session, secretName string) (env map[string]string, err error) {
+	secret, err := GetStringSecretWithContext(ctx, sess, secretName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Couldn't parse dotenv. Failed to retrieve secret.")
+	}
+	return parseDotenv(secret)
+}
+
+// parseDotenv parses a dotenv-style body into a map.
+//
+func parseDotenv(body string) (env map[string]string, err error) {
+	env = make(map[string]string)
+	for i, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		eq := strings.IndexByte(line, '=')
+		if eq < 0 {
+			return nil, errors.Errorf("Couldn't parse dotenv. Line %d has no '='.", i+1)
+		}
+
+		key := strings.TrimSpace(line[:eq])
+		if key == "" {
+			return nil, errors.Errorf("Couldn't parse dotenv. Line %d has no key.", i+1)
+		}
+
+		value, err := parseDotenvValue(strings.TrimSpace(line[eq+1:]))
+		if err != nil {
+			return nil, errors.Wrapf(err, "Couldn't parse dotenv. Invalid value for %s on line %d", key, i+1)
+		}
+		env[key] = value
+	}
+	return env, nil
+}
+
+// parseDotenvValue unquotes a single dotenv value.
+//
+func parseDotenvValue(v string) (string, error) {
+	if v == "" {
+		return "", nil
+	}
+
+	switch quote := v[0]; quote {
+	case '\'', '"':
+		end := closingQuote(v, quote)
+		if end < 0 {
+			return "", errors.New("unterminated quoted value")
+		}
+		if quote == '\'' {
+			return v[1:end], nil
+		}
+		return unescapeDotenv(v[1:end]), nil
+	}
+
+	if i := strings.Index(v, " #"); i >= 0 {
+		v = strings.TrimSpace(v[:i])
+	}
+	return v, nil
+}
+
+// closingQuote returns the index of the quote which closes v, skipping
+// backslash-escaped quotes within double quotes, or -1 if there isn't one.
+//
+func closingQuote(v string, quote byte) int {
+	for i := 1; i < len(v); i++ {
+		switch {
+		case quote == '"' && v[i] == '\\':
+			i++
+		case v[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
+
+// unescapeDotenv replaces the escape sequences allowed in double-quoted
+// dotenv values. Unknown escapes are kept as written.
+//
+func unescapeDotenv(v string) string {
+	var b strings.Builder
+	for i := 0; i < len(v); i++ {
+		if v[i] != '\\' || i+1 == len(v) {
+			b.WriteByte(v[i])
+			continue
+		}
+		i++
+		switch v[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case '"', '\\':
+			b.WriteByte(v[i])
+		default:
+			b.WriteByte('\\')
+			b.WriteByte(v[i])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,60 @@
+package awssecret
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseDotenv(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    map[string]string
+		wantErr bool
+	}{
+		{"simple", "A=1\nB=two", map[string]string{"A": "1", "B": "two"}, false},
+		{"blank lines and comments", "\n# comment\nA=1\n\n  # indented\n", map[string]string{"A": "1"}, false},
+		{"export prefix", "export A=1", map[string]string{"A": "1"}, false},
+		{"whitespace around key and value", "  A = 1  ", map[string]string{"A": "1"}, false},
+		{"empty value", "A=", map[string]string{"A": ""}, false},
+		{"value containing =", "URL=a=b", map[string]string{"URL": "a=b"}, false},
+		{"inline comment", "A=1 # one", map[string]string{"A": "1"}, false},
+		{"hash without space", "A=abc#def", map[string]string{"A": "abc#def"}, false},
+		{"single quotes are literal", `A='x\ny # z'`, map[string]string{"A": `x\ny # z`}, false},
+		{"double quote escapes", `A="x\ny\t\"q\" \\ \z"`, map[string]string{"A": "x\ny\t\"q\" \\ \\z"}, false},
+		{"later keys override", "A=1\nA=2", map[string]string{"A": "2"}, false},
+		{"crlf line endings", "A=1\r\nB=2\r\n", map[string]string{"A": "1", "B": "2"}, false},
+		{"no equals", "A=1\nB", nil, true},
+		{"no key", "=1", nil, true},
+		{"unterminated quote", `A="abc`, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDotenv(tt.body)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetDotenvSecret(t *testing.T) {
+	useDefaultAPI(t, newStringStub(map[string]string{
+		"app": "# app config\nexport DB_USER=admin\nDB_PASS='p@ss # word'\n",
+	}))
+
+	got, err := GetDotenvSecret(nil, "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"DB_USER": "admin", "DB_PASS": "p@ss # word"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	if _, err := GetDotenvSecret(nil, "missing"); err == nil {
+		t.Error("expected an error for a missing secret")
+	}
+}
@@ -0,0 +1,197 @@
+package awssecret
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/pkg/errors"
+)
+
+// VersionedSecretsManagerAPI is the subset of the AWS Secrets Manager client
+// needed to watch a secret for new versions and read them.
+//
+type VersionedSecretsManagerAPI interface {
+	SecretsManagerAPI
+	DescribeSecretAPI
+}
+
+// DSNRefresher holds a Postgres DSN built from a secret and rebuilds it
+// whenever the secret's AWSCURRENT version changes, e.g. after rotation. It
+// polls DescribeSecret, which is cheaper than reading the value, and only
+// retrieves the value when the VersionId differs. It is safe for
+// concurrent use.
+//
+type DSNRefresher struct {
+	client     VersionedSecretsManagerAPI
+	secretName string
+	onChange   func(dsn string)
+
+	mu        sync.RWMutex
+	dsn       string
+	versionID string
+	err       error
+
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewPostgresDSNRefresher builds a Postgres DSN from the named secret, as
+// GetPostgresDSNSecret does, and starts a goroutine which checks for a new
+// version of the secret every interval until Stop is called. If onChange is
+// not nil, it is called from that goroutine with the new DSN whenever the
+// version changes, so that connection pools can be rebuilt. An error is
+// returned if the initial DSN can't be built.
+//
+func NewPostgresDSNRefresher(sess *session.Session, secretName string, interval time.Duration, onChange func(dsn string)) (r *DSNRefresher, err error) {
+	svc, err := newService(sess, ClientOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return NewPostgresDSNRefresherWithClient(svc, secretName, interval, onChange)
+}
+
+// NewPostgresDSNRefresherWithClient is the same as NewPostgresDSNRefresher,
+// but uses the supplied client.
+//
+func NewPostgresDSNRefresherWithClient(client VersionedSecretsManagerAPI, secretName string, interval time.Duration, onChange func(dsn string)) (r *DSNRefresher, err error) {
+	err = validateInterval(interval)
+	if err != nil {
+		return nil, err
+	}
+
+	r = &DSNRefresher{
+		client:     client,
+		secretName: secretName,
+		onChange:   onChange,
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+
+	_, err = r.refresh(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	go r.run(interval)
+	return r, nil
+}
+
+// CurrentDSN returns the DSN built from the most recently seen version of
+// the secret.
+//
+func (r *DSNRefresher) CurrentDSN() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.dsn
+}
+
+// VersionID returns the VersionId the current DSN was built from.
+//
+func (r *DSNRefresher) VersionID() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.versionID
+}
+
+// Err returns the error from the most recent check, or nil if it
+// succeeded. A failed check leaves the previous DSN in place.
+//
+func (r *DSNRefresher) Err() error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.err
+}
+
+// Stop ends the background checks and waits for the goroutine to exit. It
+// is safe to call more than once.
+//
+func (r *DSNRefresher) Stop() {
+	r.stopOnce.Do(func() { close(r.stop) })
+	<-r.done
+}
+
+func (r *DSNRefresher) run(interval time.Duration) {
+	defer close(r.done)
+
+	ticks, stopTicker := newTicker(interval)
+	defer stopTicker()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-r.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticks:
+			changed, err := r.refresh(ctx)
+			r.mu.Lock()
+			r.err = err
+			dsn := r.dsn
+			r.mu.Unlock()
+
+			if changed && r.onChange != nil {
+				r.onChange(dsn)
+			}
+		}
+	}
+}
+
+// refresh rebuilds the DSN if the secret's current version differs from
+// the one last seen, reporting whether it did.
+//
+func (r *DSNRefresher) refresh(ctx context.Context) (changed bool, err error) {
+	result, err := describeSecret(ctx, r.client, r.secretName)
+	if err != nil {
+		return false, err
+	}
+
+	versionID := versionWithStage(result.VersionIdsToStages, DefaultVersionStage)
+	if versionID == "" {
+		return false, errors.Errorf("Secret %q has no %s version", r.secretName, DefaultVersionStage)
+	}
+	if versionID == r.VersionID() {
+		return false, nil
+	}
+
+	str, err := getStringSecret(ctx, r.client, newGetSecretValueInput(r.secretName, versionID, ""))
+	if err != nil {
+		return false, errors.Wrapf(err, "Couldn't build DSN. Failed to retrieve secret")
+	}
+
+	dsn, err := postgresDSN(str)
+	if err != nil {
+		return false, errors.Wrapf(err, "Couldn't build DSN")
+	}
+
+	r.mu.Lock()
+	r.dsn = dsn
+	r.versionID = versionID
+	r.mu.Unlock()
+
+	return true, nil
+}
+
+// versionWithStage returns the VersionId labeled with stage.
+//
+func versionWithStage(versionIDsToStages map[string][]*string, stage string) string {
+	for id, stages := range versionIDsToStages {
+		for _, s := range stages {
+			if aws.StringValue(s) == stage {
+				return id
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,117 @@
+package awssecret
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+)
+
+// versionedStub serves a secret whose AWSCURRENT version can be moved,
+// counting GetSecretValue calls.
+type versionedStub struct {
+	mu       sync.Mutex
+	versions map[string]string
+	current  string
+	gets     int
+}
+
+func (s *versionedStub) DescribeSecretWithContext(ctx context.Context, input *secretsmanager.DescribeSecretInput, opts ...request.Option) (*secretsmanager.DescribeSecretOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stages := map[string][]*string{}
+	for id := range s.versions {
+		stages[id] = nil
+	}
+	stages[s.current] = aws.StringSlice([]string{DefaultVersionStage})
+	return &secretsmanager.DescribeSecretOutput{Name: input.SecretId, VersionIdsToStages: stages}, nil
+}
+
+func (s *versionedStub) GetSecretValue(input *secretsmanager.GetSecretValueInput) (*secretsmanager.GetSecretValueOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.gets++
+	id := aws.StringValue(input.VersionId)
+	return &secretsmanager.GetSecretValueOutput{
+		Name:         input.SecretId,
+		VersionId:    aws.String(id),
+		SecretString: aws.String(s.versions[id]),
+	}, nil
+}
+
+func (s *versionedStub) rotate(id, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.versions[id] = value
+	s.current = id
+}
+
+func (s *versionedStub) getCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.gets
+}
+
+func TestDSNRefresherRebuildsOnNewVersion(t *testing.T) {
+	ticks, interval := fakeTicker(t)
+	stub := &versionedStub{versions: map[string]string{"v1": "postgres://a@host1/db"}, current: "v1"}
+
+	var changes []string
+	r, err := NewPostgresDSNRefresherWithClient(stub, "db", time.Minute, func(dsn string) { changes = append(changes, dsn) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Stop()
+
+	if r.CurrentDSN() != "postgres://a@host1/db" || r.VersionID() != "v1" {
+		t.Fatalf("initial DSN = %q (%s)", r.CurrentDSN(), r.VersionID())
+	}
+
+	// An unchanged version only costs a DescribeSecret call.
+	ticks <- time.Now()
+	ticks <- time.Now()
+	if got := stub.getCount(); got != 1 {
+		t.Errorf("GetSecretValue calls = %d, want 1", got)
+	}
+
+	stub.rotate("v2", "postgres://a@host2/db")
+	ticks <- time.Now()
+	ticks <- time.Now()
+
+	if r.CurrentDSN() != "postgres://a@host2/db" || r.VersionID() != "v2" || r.Err() != nil {
+		t.Errorf("after rotation DSN = %q (%s), err = %v", r.CurrentDSN(), r.VersionID(), r.Err())
+	}
+	if len(changes) != 1 || changes[0] != "postgres://a@host2/db" {
+		t.Errorf("onChange calls = %v, want only the rotated DSN", changes)
+	}
+	if *interval != time.Minute {
+		t.Errorf("ticker interval = %s, want 1m", *interval)
+	}
+}
+
+func TestNewPostgresDSNRefresherWithClientErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		stub     *versionedStub
+		interval time.Duration
+	}{
+		{"zero interval", &versionedStub{versions: map[string]string{"v1": "postgres://h/db"}, current: "v1"}, 0},
+		{"negative interval", &versionedStub{versions: map[string]string{"v1": "postgres://h/db"}, current: "v1"}, -time.Minute},
+		{"no current version", &versionedStub{versions: map[string]string{}}, time.Minute},
+		{"value isn't a DSN", &versionedStub{versions: map[string]string{"v1": "not json"}, current: "v1"}, time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeTicker(t)
+			r, err := NewPostgresDSNRefresherWithClient(tt.stub, "db", tt.interval, nil)
+			if err == nil {
+				r.Stop()
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package awssecret
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/pkg/errors"
+)
+
+// dsnBuilders maps the engine field of RDS-style secrets to the function
+// which builds a DSN for that engine.
+var dsnBuilders = map[string]func(str string) (string, error){
+	"postgres":          postgresDSN,
+	"aurora-postgresql": postgresDSN,
+	"mysql":             func(str string) (string, error) { return mysqlDSN(str, MySQLDSNOptions{}) },
+	"mariadb":           func(str string) (string, error) { return mysqlDSN(str, MySQLDSNOptions{}) },
+	"aurora-mysql":      func(str string) (string, error) { return mysqlDSN(str, MySQLDSNOptions{}) },
+	"sqlserver":         sqlServerDSN,
+	"oracle":            oracleDSN,
+}
+
+// GetCredentialDSNSecret retrieves the named secret from AWS Secrets Manager
+// and returns both the decoded Credential and a connection string built for
+// the database named by its engine field: postgres (as
+// GetPostgresDSNSecret), mysql or mariadb (as GetMySQLDSNSecret),
+// sqlserver (as GetSQLServerDSNSecret) or oracle (as GetOracleDSNSecret).
+// An error is returned for any other engine.
+//
+func GetCredentialDSNSecret(sess *session.Session, secretName string) (cred *Credential, dsnStr string, err error) {
+	return GetCredentialDSNSecretWithContext(context.Background(), sess, secretName)
+}
+
+// GetCredentialDSNSecretWithContext is the same as GetCredentialDSNSecret,
+// but uses the supplied context for the underlying AWS request.
+//
+func GetCredentialDSNSecretWithContext(ctx context.Context, sess *session.Session, secretName string) (cred *Credential, dsnStr string, err error) {
+	str, err := GetStringSecretWithContext(ctx, sess, secretName)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "Couldn't build credential. Failed to retrieve secret.")
+	}
+
+	cred = &Credential{}
+	err = Unmarshal([]byte(str), cred)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "Couldn't build credential. Failed to decode JSON.")
+	}
+
+	build, ok := dsnBuilders[cred.Engine]
+	if !ok {
+		return cred, "", errors.Errorf("Couldn't build DSN. Unsupported engine %q", cred.Engine)
+	}
+
+	dsnStr, err = build(str)
+	if err != nil {
+		return cred, "", errors.Wrapf(err, "Couldn't build DSN")
+	}
+
+	return cred, dsnStr, nil
+}
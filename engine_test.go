@@ -0,0 +1,50 @@
+package awssecret
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGetCredentialDSNSecret(t *testing.T) {
+	const fields = `"host":"db.local","port":1234,"username":"admin","password":"pw","dbname":"app"`
+
+	tests := []struct {
+		engine  string
+		want    string
+		wantErr bool
+	}{
+		{"postgres", "host=db.local port=1234 dbname=app user=admin password=pw ", false},
+		{"aurora-postgresql", "host=db.local port=1234 dbname=app user=admin password=pw ", false},
+		{"mysql", "admin:pw@tcp(db.local:1234)/app", false},
+		{"mariadb", "admin:pw@tcp(db.local:1234)/app", false},
+		{"aurora-mysql", "admin:pw@tcp(db.local:1234)/app", false},
+		{"sqlserver", "sqlserver://admin:pw@db.local:1234?database=app", false},
+		{"oracle", "admin/pw@db.local:1234/app", false},
+		{"mongo", "", true},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.engine, func(t *testing.T) {
+			sess := newStringSecretsSession(t, map[string]string{
+				"db": `{"engine":"` + tt.engine + `",` + fields + `}`,
+			})
+
+			cred, got, err := GetCredentialDSNSecret(sess, "db")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("DSN = %q, want %q", got, tt.want)
+			}
+			if tt.wantErr {
+				if err != nil && !strings.Contains(err.Error(), "Unsupported engine") {
+					t.Errorf("err = %v, want an unsupported engine error", err)
+				}
+				return
+			}
+			if cred.Engine != tt.engine || cred.Username != "admin" {
+				t.Errorf("cred = %+v", cred)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package awssecret
+
+import (
+	"context"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/pkg/errors"
+)
+
+// setenv is os.Setenv, replaceable in tests.
+var setenv = os.Setenv
+
+// EnvOptions controls how a JSON secret is converted into environment
+// variables.
+//
+type EnvOptions struct {
+	// SkipNonString ignores keys whose values aren't JSON strings, rather
+	// than returning an error.
+	SkipNonString bool
+}
+
+// LoadSecretAsEnvMap retrieves the named secret from AWS Secrets Manager and
+// decodes it as a JSON object of string values, returning them as a map of
+// environment variable names to values. The environment isn't modified. An
+// error is returned if any value isn't a string.
+//
+func LoadSecretAsEnvMap(sess *session.Session, secretName string) (env map[string]string, err error) {
+	return LoadSecretAsEnvMapWithOptionsContext(context.Background(), sess, secretName, EnvOptions{})
+}
+
+// LoadSecretAsEnvMapWithContext is the same as LoadSecretAsEnvMap, but uses
+// the supplied context for the underlying AWS request.
+//
+func LoadSecretAsEnvMapWithContext(ctx context.Context, sess *session.Session, secretName string) (env map[string]string, err error) {
+	return LoadSecretAsEnvMapWithOptionsContext(ctx, sess, secretName, EnvOptions{})
+}
+
+// LoadSecretAsEnvMapWithOptions is the same as LoadSecretAsEnvMap, but
+// converts the secret according to opts.
+//
+func LoadSecretAsEnvMapWithOptions(sess *session.Session, secretName string, opts EnvOptions) (env map[string]string, err error) {
+	return LoadSecretAsEnvMapWithOptionsContext(context.Background(), sess, secretName, opts)
+}
+
+// LoadSecretAsEnvMapWithOptionsContext is the same as
+// LoadSecretAsEnvMapWithOptions, but uses the supplied context for the
+// underlying AWS request.
+//
+func LoadSecretAsEnvMapWithOptionsContext(ctx context.Context, sess *session.Session, secretName string, opts EnvOptions) (env map[string]string, err error) {
+	secret, err := GetStringSecretWithContext(ctx, sess, secretName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Couldn't load environment. Failed to retrieve secret.")
+	}
+
+	var obj map[string]interface{}
+	err = Unmarshal([]byte(secret), &obj)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Couldn't load environment. Failed to decode JSON.")
+	}
+
+	env = make(map[string]string, len(obj))
+	for k, v := range obj {
+		s, ok := v.(string)
+		if !ok {
+			if opts.SkipNonString {
+				continue
+			}
+			return nil, errors.Errorf("Couldn't load environment. Value of key %q is not a string.", k)
+		}
+		env[k] = s
+	}
+
+	return env, nil
+}
+
+// ExportSecretToEnv retrieves the named secret as LoadSecretAsEnvMap does
+// and sets each of its keys as an environment variable of the current
+// process.
+//
+func ExportSecretToEnv(sess *session.Session, secretName string) error {
+	return ExportSecretToEnvWithOptionsContext(context.Background(), sess, secretName, EnvOptions{})
+}
+
+// ExportSecretToEnvWithContext is the same as ExportSecretToEnv, but uses
+// the supplied context for the underlying AWS request.
+//
+func ExportSecretToEnvWithContext(ctx context.Context, sess *session.Session, secretName string) error {
+	return ExportSecretToEnvWithOptionsContext(ctx, sess, secretName, EnvOptions{})
+}
+
+// ExportSecretToEnvWithOptions is the same as ExportSecretToEnv, but
+// converts the secret according to opts.
+//
+func ExportSecretToEnvWithOptions(sess *session.Session, secretName string, opts EnvOptions) error {
+	return ExportSecretToEnvWithOptionsContext(context.Background(), sess, secretName, opts)
+}
+
+// ExportSecretToEnvWithOptionsContext is the same as
+// ExportSecretToEnvWithOptions, but uses the supplied context for the
+// underlying AWS request.
+//
+func ExportSecretToEnvWithOptionsContext(ctx context.Context, sess *session.Session, secretName string, opts EnvOptions) error {
+	env, err := LoadSecretAsEnvMapWithOptionsContext(ctx, sess, secretName, opts)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		err = setenv(k, env[k])
+		if err != nil {
+			return errors.Wrapf(err, "Couldn't set environment variable %s", k)
+		}
+	}
+
+	return nil
+}
+
+// FormatSecretAsShellExports retrieves the named secret as LoadSecretAsEnvMap
+// does and formats it as export KEY='value' lines, sorted by key, which can
+// be passed to a shell's eval. Values are single-quoted, so they are never
+// expanded by the shell. An error is returned if any key isn't a valid
+// shell variable name.
+//
+func FormatSecretAsShellExports(sess *session.Session, secretName string) (exports string, err error) {
+	return FormatSecretAsShellExportsWithContext(context.Background(), sess, secretName)
+}
+
+// FormatSecretAsShellExportsWithContext is the same as
+// FormatSecretAsShellExports, but uses the supplied context for the
+// underlying AWS request.
+//
+func FormatSecretAsShellExportsWithContext(ctx context.Context, sess *session.Session, secretName string) (exports string, err error) {
+	env, err := LoadSecretAsEnvMapWithContext(ctx, sess, secretName)
+	if err != nil {
+		return "", err
+	}
+	return shellExports(env)
+}
+
+// shellExports formats env as export statements.
+//
+func shellExports(env map[string]string) (string, error) {
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		if !isShellName(k) {
+			return "", errors.Errorf("Couldn't format exports. Key %q is not a valid shell variable name.", k)
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		b.WriteString("export ")
+		b.WriteString(k)
+		b.WriteString("=")
+		b.WriteString(shellQuote(env[k]))
+		b.WriteString("\n")
+	}
+	return b.String(), nil
+}
+
+// shellQuote wraps s in single quotes. Each single quote within s closes
+// the quoted string, is written backslash-escaped, and reopens it.
+// Everything else, including newlines, is literal within single quotes.
+//
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// isShellName reports whether s is a valid POSIX shell variable name.
+//
+func isShellName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		if r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || i > 0 && r >= '0' && r <= '9' {
+			continue
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,156 @@
+package awssecret
+
+import (
+	"errors"
+	"os/exec"
+	"reflect"
+	"testing"
+)
+
+// fakeSetenv replaces setenv for the rest of the test, recording each call
+// in order. Setting the key named fail returns an error.
+func fakeSetenv(t *testing.T, fail string) *[]string {
+	t.Helper()
+	var calls []string
+	previous := setenv
+	setenv = func(k, v string) error {
+		if k == fail {
+			return errors.New("setenv failed")
+		}
+		calls = append(calls, k+"="+v)
+		return nil
+	}
+	t.Cleanup(func() { setenv = previous })
+	return &calls
+}
+
+func TestLoadSecretAsEnvMap(t *testing.T) {
+	sess := newStringSecretsSession(t, map[string]string{
+		"strings": `{"DB_HOST":"db.local","DB_USER":"admin"}`,
+		"mixed":   `{"DB_HOST":"db.local","DB_PORT":5432}`,
+		"array":   `["a"]`,
+	})
+
+	tests := []struct {
+		secret  string
+		opts    EnvOptions
+		want    map[string]string
+		wantErr bool
+	}{
+		{"strings", EnvOptions{}, map[string]string{"DB_HOST": "db.local", "DB_USER": "admin"}, false},
+		{"mixed", EnvOptions{}, nil, true},
+		{"mixed", EnvOptions{SkipNonString: true}, map[string]string{"DB_HOST": "db.local"}, false},
+		{"array", EnvOptions{}, nil, true},
+		{"missing", EnvOptions{}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.secret, func(t *testing.T) {
+			got, err := LoadSecretAsEnvMapWithOptions(sess, tt.secret, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExportSecretToEnv(t *testing.T) {
+	sess := newStringSecretsSession(t, map[string]string{
+		"env": `{"B":"2","A":"1","C":"3"}`,
+	})
+
+	tests := []struct {
+		name    string
+		fail    string
+		want    []string
+		wantErr bool
+	}{
+		{"sets every key in order", "", []string{"A=1", "B=2", "C=3"}, false},
+		{"stops at the first failure", "B", []string{"A=1"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := fakeSetenv(t, tt.fail)
+			err := ExportSecretToEnv(sess, "env")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(*calls, tt.want) {
+				t.Errorf("setenv calls = %v, want %v", *calls, tt.want)
+			}
+		})
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"plain", `'plain'`},
+		{"", `''`},
+		{"it's", `'it'\''s'`},
+		{"''", `''\'''\'''`},
+		{"line1\nline2", "'line1\nline2'"},
+		{`$HOME "x" \n ` + "`id`", `'$HOME "x" \n ` + "`id`'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := shellQuote(tt.value); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatSecretAsShellExports(t *testing.T) {
+	sess := newStringSecretsSession(t, map[string]string{
+		"env":      `{"PASSWORD":"it's a \"secret\"\n$HOME","DB_HOST":"db.local","_X1":""}`,
+		"bad-name": `{"DB-HOST":"db.local"}`,
+		"digit":    `{"1DB":"db.local"}`,
+		"number":   `{"PORT":5432}`,
+	})
+
+	got, err := FormatSecretAsShellExports(sess, "env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "export DB_HOST='db.local'\n" +
+		"export PASSWORD='it'\\''s a \"secret\"\n$HOME'\n" +
+		"export _X1=''\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	for _, secret := range []string{"bad-name", "digit", "number", "missing"} {
+		if _, err := FormatSecretAsShellExports(sess, secret); err == nil {
+			t.Errorf("%s: expected an error", secret)
+		}
+	}
+}
+
+func TestShellExportsRoundTripThroughShell(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh not available")
+	}
+
+	values := []string{"plain", "it's", "line1\nline2\n", `$HOME "x" \ ` + "`id`", "'; echo pwned; '"}
+	for _, value := range values {
+		t.Run(value, func(t *testing.T) {
+			exports, err := shellExports(map[string]string{"V": value})
+			if err != nil {
+				t.Fatal(err)
+			}
+			out, err := exec.Command(sh, "-c", exports+`printf %s "$V"`).Output()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(out) != value {
+				t.Errorf("shell saw %q, want %q", out, value)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package awssecret
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrSecretNotFound is matched (via errors.Is) by errors caused by a
+	// ResourceNotFoundException from AWS Secrets Manager, or a
+	// ParameterNotFound from SSM Parameter Store.
+	ErrSecretNotFound = errors.New("secret not found")
+
+	// ErrInvalidRequest is matched (via errors.Is) by errors caused by an
+	// InvalidRequestException, such as reading a secret marked for deletion.
+	ErrInvalidRequest = errors.New("invalid request")
+
+	// ErrDecryptionFailure is matched (via errors.Is) by errors caused by a
+	// DecryptionFailure, usually a missing KMS key permission.
+	ErrDecryptionFailure = errors.New("secret could not be decrypted")
+
+	// ErrSecretEmpty is returned when a secret has neither a string nor a
+	// binary value, and by StrictGetStringSecret when a secret's value is
+	// empty or only whitespace.
+	ErrSecretEmpty = errors.New("secret is empty")
+
+	// ErrSecretIsBinary is returned when a string value is requested for a
+	// secret which only has a binary value. Use GetBinarySecret to read it.
+	ErrSecretIsBinary = errors.New("secret is binary, not a string")
+
+	// ErrSecretIsString is returned when a binary value is requested for a
+	// secret which only has a string value. Use GetStringSecret to read it.
+	ErrSecretIsString = errors.New("secret is a string, not binary")
+
+	// ErrSecretExpired is returned by GetExpiringSecret when the secret's
+	// expiry time has passed.
+	ErrSecretExpired = errors.New("secret has expired")
+
+	// ErrMultipleSecrets is returned by GetStringSecretByTags when more
+	// than one secret carries the requested tags.
+	ErrMultipleSecrets = errors.New("more than one secret matches")
+
+	// ErrNoSession is returned, after SetRequireSession(true), by any call
+	// made without a session.
+	ErrNoSession = errors.New("no AWS session supplied")
+
+	// ErrMalformedARN is returned, without calling AWS, when a secret ID
+	// starts with "arn:" but isn't a well-formed Secrets Manager ARN.
+	ErrMalformedARN = errors.New("malformed secret ARN")
+)
+
+// sentinelErrors maps AWS error codes to the sentinel errors callers can test
+// for with errors.Is.
+var sentinelErrors = map[string]error{
+	secretsmanager.ErrCodeResourceNotFoundException: ErrSecretNotFound,
+	secretsmanager.ErrCodeInvalidRequestException:   ErrInvalidRequest,
+	secretsmanager.ErrCodeDecryptionFailure:         ErrDecryptionFailure,
+	ssm.ErrCodeParameterNotFound:                    ErrSecretNotFound,
+}
+
+// SecretError describes a failed AWS request for a secret. It is returned
+// (wrapped) by the package's functions whenever AWS reports an error, and
+// can be retrieved with errors.As. It also matches the corresponding
+// sentinel error (e.g. ErrSecretNotFound) via errors.Is, and unwraps to
+// the original AWS SDK error.
+//
+type SecretError struct {
+	SecretName string
+	Code       string
+	Message    string
+	RequestID  string
+	StatusCode int
+
+	err error
+}
+
+func (e *SecretError) Error() string { return e.err.Error() }
+
+// Unwrap returns the original error from the AWS SDK.
+func (e *SecretError) Unwrap() error { return e.err }
+
+// Is reports whether target is the sentinel error for e's code.
+func (e *SecretError) Is(target error) bool {
+	sentinel, ok := sentinelErrors[e.Code]
+	return ok && target == sentinel
+}
+
+// mapAWSError converts an awserr.Error concerning secretName into a
+// *SecretError, or returns err unchanged if it didn't come from AWS.
+//
+func mapAWSError(secretName string, err error) error {
+	aerr, ok := err.(awserr.Error)
+	if !ok {
+		return err
+	}
+
+	serr := &SecretError{
+		SecretName: secretName,
+		Code:       aerr.Code(),
+		Message:    aerr.Message(),
+		err:        err,
+	}
+	if rerr, ok := err.(awserr.RequestFailure); ok {
+		serr.RequestID = rerr.RequestID()
+		serr.StatusCode = rerr.StatusCode()
+	}
+	return serr
+}
+
+// awsManagedKMSKey is the key Secrets Manager uses when a secret doesn't
+// name one.
+const awsManagedKMSKey = "alias/aws/secretsmanager"
+
+// DecryptionError is returned (wrapped) when AWS Secrets Manager reports a
+// DecryptionFailure for a secret, which almost always means the caller
+// lacks kms:Decrypt permission on the secret's KMS key. It matches
+// ErrDecryptionFailure via errors.Is, and unwraps to the *SecretError.
+//
+type DecryptionError struct {
+	SecretName string
+
+	// KMSKeyID is the key the secret is encrypted with, as reported by
+	// DescribeSecret. It is empty if the key couldn't be determined.
+	KMSKeyID string
+
+	err error
+}
+
+func (e *DecryptionError) Error() string {
+	key := "the secret's KMS key"
+	if e.KMSKeyID != "" {
+		key = fmt.Sprintf("KMS key %q", e.KMSKeyID)
+	}
+	return fmt.Sprintf("%s (check that the caller has kms:Decrypt permission on %s, and that the key policy allows it)", e.err.Error(), key)
+}
+
+// Unwrap returns the underlying *SecretError.
+func (e *DecryptionError) Unwrap() error { return e.err }
+
+// Is reports whether target is ErrDecryptionFailure.
+func (e *DecryptionError) Is(target error) bool { return target == ErrDecryptionFailure }
+
+// enrichDecryptionFailure converts a DecryptionFailure from AWS into a
+// *DecryptionError, looking up the secret's KMS key with DescribeSecret when
+// client supports it. Any other error is returned unchanged, as is err if
+// the lookup fails.
+//
+func enrichDecryptionFailure(ctx context.Context, client interface{}, secretName string, err error) error {
+	serr, ok := err.(*SecretError)
+	if !ok || serr.Code != secretsmanager.ErrCodeDecryptionFailure {
+		return err
+	}
+
+	derr := &DecryptionError{SecretName: secretName, err: err}
+	if describer, ok := client.(DescribeSecretAPI); ok {
+		result, dErr := describer.DescribeSecretWithContext(ctx, &secretsmanager.DescribeSecretInput{
+			SecretId: aws.String(secretName),
+		})
+		if dErr == nil {
+			derr.KMSKeyID = aws.StringValue(result.KmsKeyId)
+			if derr.KMSKeyID == "" {
+				derr.KMSKeyID = awsManagedKMSKey
+			}
+		}
+	}
+	return derr
+}
@@ -0,0 +1,217 @@
+package awssecret
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+)
+
+func TestSentinelErrors(t *testing.T) {
+	tests := []struct {
+		code string
+		want error
+	}{
+		{secretsmanager.ErrCodeResourceNotFoundException, ErrSecretNotFound},
+		{secretsmanager.ErrCodeInvalidRequestException, ErrInvalidRequest},
+		{secretsmanager.ErrCodeDecryptionFailure, ErrDecryptionFailure},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			_, err := GetStringSecretWithClient(newErrorStub(awserr.New(tt.code, "failed", nil)), "db")
+			if !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want it to match %v", err, tt.want)
+			}
+			for _, other := range []error{ErrSecretNotFound, ErrInvalidRequest, ErrDecryptionFailure} {
+				if other != tt.want && errors.Is(err, other) {
+					t.Errorf("err also matches %v", other)
+				}
+			}
+		})
+	}
+}
+
+func TestSentinelErrorsUnmappedCodes(t *testing.T) {
+	tests := []error{
+		awserr.New(secretsmanager.ErrCodeInternalServiceError, "failed", nil),
+		errors.New("not from AWS"),
+	}
+	for _, awsErr := range tests {
+		t.Run(awsErr.Error(), func(t *testing.T) {
+			_, err := GetStringSecretWithClient(newErrorStub(awsErr), "db")
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if errors.Is(err, ErrSecretNotFound) || errors.Is(err, ErrInvalidRequest) || errors.Is(err, ErrDecryptionFailure) {
+				t.Errorf("err = %v matches a sentinel", err)
+			}
+			if !errors.Is(err, awsErr) {
+				t.Errorf("err = %v doesn't unwrap to the original error", err)
+			}
+		})
+	}
+}
+
+func TestGetSecretValueErrorMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"AWS error", awserr.New(secretsmanager.ErrCodeResourceNotFoundException, "not found", nil), `Failed to get secret "db" from AWS Secrets Manager: ResourceNotFoundException: ResourceNotFoundException: not found`},
+		{"other error", errors.New("connection reset"), `Failed to get secret "db" from AWS Secrets Manager: Unknown error: connection reset`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := GetStringSecretWithClient(newErrorStub(tt.err), "db")
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSecretError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want SecretError
+	}{
+		{
+			"request failure",
+			awserr.NewRequestFailure(awserr.New(secretsmanager.ErrCodeResourceNotFoundException, "can't find it", nil), 400, "req-1234"),
+			SecretError{SecretName: "db", Code: secretsmanager.ErrCodeResourceNotFoundException, Message: "can't find it", RequestID: "req-1234", StatusCode: 400},
+		},
+		{
+			"throttled",
+			awserr.NewRequestFailure(awserr.New("ThrottlingException", "slow down", nil), 429, "req-5678"),
+			SecretError{SecretName: "db", Code: "ThrottlingException", Message: "slow down", RequestID: "req-5678", StatusCode: 429},
+		},
+		{
+			"no request",
+			awserr.New(request.CanceledErrorCode, "canceled", nil),
+			SecretError{SecretName: "db", Code: request.CanceledErrorCode, Message: "canceled"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := GetStringSecretWithClient(newErrorStub(tt.err), "db")
+
+			var serr *SecretError
+			if !errors.As(err, &serr) {
+				t.Fatalf("err = %v, want a *SecretError", err)
+			}
+			got := *serr
+			got.err = nil
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Errorf("err = %v doesn't unwrap to the original error", err)
+			}
+		})
+	}
+}
+
+func TestSecretErrorNotFromAWS(t *testing.T) {
+	_, err := GetStringSecretWithClient(newErrorStub(errors.New("connection reset")), "db")
+	var serr *SecretError
+	if errors.As(err, &serr) {
+		t.Errorf("err = %v is a *SecretError, want a plain error", err)
+	}
+}
+
+// describingStub adds DescribeSecret to a stubClient, so that a
+// DecryptionFailure can be enriched with the secret's KMS key.
+type describingStub struct {
+	*stubClient
+	*describeStub
+}
+
+func TestDecryptionError(t *testing.T) {
+	decryptionFailure := awserr.New(secretsmanager.ErrCodeDecryptionFailure, "can't decrypt", nil)
+
+	tests := []struct {
+		name        string
+		api         SecretsManagerAPI
+		wantKMSKey  string
+		wantMessage string
+	}{
+		{
+			"customer managed key",
+			describingStub{newErrorStub(decryptionFailure), &describeStub{outputs: map[string]*secretsmanager.DescribeSecretOutput{
+				"db": {Name: aws.String("db"), KmsKeyId: aws.String(testKMSKeyARN)},
+			}}},
+			testKMSKeyARN,
+			`KMS key "` + testKMSKeyARN + `"`,
+		},
+		{
+			"AWS managed key",
+			describingStub{newErrorStub(decryptionFailure), &describeStub{outputs: map[string]*secretsmanager.DescribeSecretOutput{
+				"db": {Name: aws.String("db")},
+			}}},
+			"alias/aws/secretsmanager",
+			`KMS key "alias/aws/secretsmanager"`,
+		},
+		{
+			"describe fails",
+			describingStub{newErrorStub(decryptionFailure), &describeStub{}},
+			"",
+			"the secret's KMS key",
+		},
+		{
+			"client can't describe",
+			newErrorStub(decryptionFailure),
+			"",
+			"the secret's KMS key",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := GetStringSecretWithClient(tt.api, "db")
+
+			var derr *DecryptionError
+			if !errors.As(err, &derr) {
+				t.Fatalf("err = %v, want a *DecryptionError", err)
+			}
+			if derr.SecretName != "db" {
+				t.Errorf("SecretName = %q, want db", derr.SecretName)
+			}
+			if derr.KMSKeyID != tt.wantKMSKey {
+				t.Errorf("KMSKeyID = %q, want %q", derr.KMSKeyID, tt.wantKMSKey)
+			}
+			for _, want := range []string{"kms:Decrypt", tt.wantMessage} {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("err %q doesn't contain %q", err, want)
+				}
+			}
+			if !errors.Is(err, ErrDecryptionFailure) {
+				t.Errorf("err = %v, want it to match ErrDecryptionFailure", err)
+			}
+			var serr *SecretError
+			if !errors.As(err, &serr) || serr.Code != secretsmanager.ErrCodeDecryptionFailure {
+				t.Errorf("err = %v doesn't unwrap to the *SecretError", err)
+			}
+		})
+	}
+}
+
+func TestDecryptionErrorOnlyForDecryptionFailures(t *testing.T) {
+	api := describingStub{
+		newErrorStub(awserr.New(secretsmanager.ErrCodeResourceNotFoundException, "not found", nil)),
+		&describeStub{},
+	}
+	_, err := GetStringSecretWithClient(api, "db")
+
+	var derr *DecryptionError
+	if errors.As(err, &derr) {
+		t.Errorf("err = %v is a *DecryptionError", err)
+	}
+	if api.describeStub.calls != 0 {
+		t.Errorf("DescribeSecret called %d times, want 0", api.describeStub.calls)
+	}
+}
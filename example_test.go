@@ -0,0 +1,53 @@
+package awssecret_test
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/adlio/awssecret"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+)
+
+type stubClient struct {
+	values map[string]string
+}
+
+func (s stubClient) GetSecretValue(in *secretsmanager.GetSecretValueInput) (*secretsmanager.GetSecretValueOutput, error) {
+	v, ok := s.values[*in.SecretId]
+	if !ok {
+		return nil, awserr.New(secretsmanager.ErrCodeResourceNotFoundException, "not found", nil)
+	}
+	return &secretsmanager.GetSecretValueOutput{SecretString: aws.String(v)}, nil
+}
+
+func ExampleGetStringSecretWithClient() {
+	client := stubClient{values: map[string]string{"my/secret": "hunter2"}}
+
+	v, err := awssecret.GetStringSecretWithClient(client, "my/secret")
+	fmt.Println(v, err)
+
+	_, err = awssecret.GetStringSecretWithClient(client, "other/secret")
+	fmt.Println(errors.Is(err, awssecret.ErrSecretNotFound))
+
+	// Output:
+	// hunter2 <nil>
+	// true
+}
+
+func ExampleNewClientWithAPI() {
+	client := awssecret.NewClientWithAPI(stubClient{values: map[string]string{
+		"prod/db": `{"host":"db.local","port":5432,"dbname":"app","username":"admin","password":"pw"}`,
+	}})
+
+	dsn, err := client.GetPostgresDSN("prod/db")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("%q\n", dsn)
+
+	// Output:
+	// "host=db.local port=5432 dbname=app user=admin password=pw "
+}
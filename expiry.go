@@ -0,0 +1,67 @@
+package awssecret
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/pkg/errors"
+)
+
+// DefaultExpiryField is the JSON field GetExpiringSecret reads the expiry
+// time from when no field name is given.
+const DefaultExpiryField = "expiresAt"
+
+// GetExpiringSecret retrieves the named secret from AWS Secrets Manager,
+// JSON-decodes it into a new value of type T and reads the RFC 3339
+// timestamp in expiryField (DefaultExpiryField if empty). If that time has
+// passed, the decoded value and expiry are returned along with an error
+// matching ErrSecretExpired. Secrets without the field never expire, and
+// the zero time is returned.
+//
+func GetExpiringSecret[T any](sess *session.Session, secretName, expiryField string) (v *T, expiry time.Time, err error) {
+	return GetExpiringSecretWithContext[T](context.Background(), sess, secretName, expiryField)
+}
+
+// GetExpiringSecretWithContext is the same as GetExpiringSecret, but uses
+// the supplied context for the underlying AWS request.
+//
+func GetExpiringSecretWithContext[T any](ctx context.Context, sess *session.Session, secretName, expiryField string) (v *T, expiry time.Time, err error) {
+	if expiryField == "" {
+		expiryField = DefaultExpiryField
+	}
+
+	raw, err := GetRawJSONSecretWithContext(ctx, sess, secretName)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	v = new(T)
+	err = Unmarshal(raw, v)
+	if err != nil {
+		return nil, time.Time{}, errors.Wrapf(err, "Couldn't decode secret. Failed to decode JSON.")
+	}
+
+	var fields map[string]json.RawMessage
+	err = Unmarshal(raw, &fields)
+	if err != nil {
+		return nil, time.Time{}, errors.Wrapf(err, "Couldn't read expiry. Secret %q is not a JSON object.", secretName)
+	}
+
+	field, ok := fields[expiryField]
+	if !ok || string(field) == "null" {
+		return v, time.Time{}, nil
+	}
+
+	err = Unmarshal(field, &expiry)
+	if err != nil {
+		return nil, time.Time{}, errors.Wrapf(err, "Couldn't read expiry. Field %q is not an RFC 3339 timestamp.", expiryField)
+	}
+
+	if !time.Now().Before(expiry) {
+		return v, expiry, errors.Wrapf(ErrSecretExpired, "Secret %q expired at %s", secretName, expiry.Format(time.RFC3339))
+	}
+
+	return v, expiry, nil
+}
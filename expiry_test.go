@@ -0,0 +1,61 @@
+package awssecret
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestGetExpiringSecret(t *testing.T) {
+	type token struct {
+		Token string `json:"token"`
+	}
+	past := time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)
+	future := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+
+	sess := newStringSecretsSession(t, map[string]string{
+		"valid":      `{"token":"abc","expiresAt":"` + future.Format(time.RFC3339) + `"}`,
+		"expired":    `{"token":"abc","expiresAt":"` + past.Format(time.RFC3339) + `"}`,
+		"no-expiry":  `{"token":"abc"}`,
+		"null":       `{"token":"abc","expiresAt":null}`,
+		"custom":     `{"token":"abc","validUntil":"` + past.Format(time.RFC3339) + `"}`,
+		"bad-expiry": `{"token":"abc","expiresAt":"tomorrow"}`,
+		"array":      `[{"token":"abc"}]`,
+	})
+
+	tests := []struct {
+		secret      string
+		field       string
+		wantExpiry  time.Time
+		wantExpired bool
+		wantErr     bool
+	}{
+		{"valid", "", future, false, false},
+		{"expired", "", past, true, true},
+		{"expired", "expiresAt", past, true, true},
+		{"no-expiry", "", time.Time{}, false, false},
+		{"null", "", time.Time{}, false, false},
+		{"custom", "validUntil", past, true, true},
+		{"custom", "", time.Time{}, false, false},
+		{"bad-expiry", "", time.Time{}, false, true},
+		{"array", "", time.Time{}, false, true},
+		{"missing", "", time.Time{}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.secret+"/"+tt.field, func(t *testing.T) {
+			v, expiry, err := GetExpiringSecret[token](sess, tt.secret, tt.field)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := errors.Is(err, ErrSecretExpired); got != tt.wantExpired {
+				t.Errorf("errors.Is(err, ErrSecretExpired) = %v, want %v", got, tt.wantExpired)
+			}
+			if !expiry.Equal(tt.wantExpiry) {
+				t.Errorf("expiry = %v, want %v", expiry, tt.wantExpiry)
+			}
+			if (!tt.wantErr || tt.wantExpired) && (v == nil || v.Token != "abc") {
+				t.Errorf("v = %+v, want the decoded token", v)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package awssecret
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/pkg/errors"
+)
+
+// GetCredentialSecretWithExtras is the same as GetCredentialSecret, but
+// also returns the secret's fields which don't correspond to a field of
+// Credential, undecoded. The map is empty, not nil, if there are none.
+//
+func GetCredentialSecretWithExtras(sess *session.Session, secretName string) (cred *Credential, extras map[string]json.RawMessage, err error) {
+	return GetCredentialSecretWithExtrasContext(context.Background(), sess, secretName)
+}
+
+// GetCredentialSecretWithExtrasContext is the same as
+// GetCredentialSecretWithExtras, but uses the supplied context for the
+// underlying AWS request.
+//
+func GetCredentialSecretWithExtrasContext(ctx context.Context, sess *session.Session, secretName string) (cred *Credential, extras map[string]json.RawMessage, err error) {
+	secret, err := GetStringSecretWithContext(ctx, sess, secretName)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "Couldn't decode secret. Failed to retrieve secret.")
+	}
+
+	cred = &Credential{}
+	extras, err = decodeWithExtras([]byte(secret), cred)
+	if err != nil {
+		return cred, nil, errors.Wrapf(err, "Couldn't decode secret. Failed to decode JSON.")
+	}
+	return cred, extras, nil
+}
+
+// decodeWithExtras decodes the JSON object data into the struct pointed to
+// by v, and returns the object's remaining fields. Field names are matched
+// case-insensitively, as encoding/json does.
+//
+func decodeWithExtras(data []byte, v interface{}) (extras map[string]json.RawMessage, err error) {
+	err = Unmarshal(data, v)
+	if err != nil {
+		return nil, err
+	}
+
+	err = Unmarshal(data, &extras)
+	if err != nil {
+		return nil, err
+	}
+	if extras == nil {
+		extras = make(map[string]json.RawMessage)
+	}
+
+	known := jsonFieldNames(reflect.TypeOf(v).Elem())
+	for k := range extras {
+		if known[strings.ToLower(k)] {
+			delete(extras, k)
+		}
+	}
+	return extras, nil
+}
+
+// jsonFieldNames returns the lower-cased JSON names of the exported fields
+// of struct type t.
+//
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	names := make(map[string]bool, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name := f.Name
+		if tag, ok := f.Tag.Lookup("json"); ok {
+			tagName, _, _ := strings.Cut(tag, ",")
+			if tagName == "-" {
+				continue
+			}
+			if tagName != "" {
+				name = tagName
+			}
+		}
+		names[strings.ToLower(name)] = true
+	}
+	return names
+}
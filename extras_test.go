@@ -0,0 +1,83 @@
+package awssecret
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeWithExtras(t *testing.T) {
+	type tagged struct {
+		Name    string `json:"name"`
+		Ignored string `json:"-"`
+		Omitted string `json:",omitempty"`
+		Plain   string
+		private string
+	}
+
+	tests := []struct {
+		name       string
+		data       string
+		want       tagged
+		wantExtras map[string]json.RawMessage
+		wantErr    bool
+	}{
+		{"no extras", `{"name":"a","Plain":"b"}`, tagged{Name: "a", Plain: "b"}, map[string]json.RawMessage{}, false},
+		{"extras are raw", `{"name":"a","region":"us-east-1","replicas":[1,2]}`, tagged{Name: "a"}, map[string]json.RawMessage{
+			"region":   json.RawMessage(`"us-east-1"`),
+			"replicas": json.RawMessage(`[1,2]`),
+		}, false},
+		{"names match case-insensitively", `{"NAME":"a","plain":"b","omitted":"c"}`, tagged{Name: "a", Plain: "b", Omitted: "c"}, map[string]json.RawMessage{}, false},
+		{"json:\"-\" fields are extras", `{"Ignored":"x"}`, tagged{}, map[string]json.RawMessage{"Ignored": json.RawMessage(`"x"`)}, false},
+		{"unexported fields are extras", `{"private":"x"}`, tagged{}, map[string]json.RawMessage{"private": json.RawMessage(`"x"`)}, false},
+		{"null", `null`, tagged{}, map[string]json.RawMessage{}, false},
+		{"not an object", `["a"]`, tagged{}, nil, true},
+		{"not json", `hunter2`, tagged{}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got tagged
+			extras, err := decodeWithExtras([]byte(tt.data), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+			if !reflect.DeepEqual(extras, tt.wantExtras) {
+				t.Errorf("extras = %s, want %s", extras, tt.wantExtras)
+			}
+		})
+	}
+}
+
+func TestGetCredentialSecretWithExtras(t *testing.T) {
+	useDefaultAPI(t, newStringStub(map[string]string{
+		"db":    `{"engine":"postgres","host":"db.local","port":5432,"username":"admin","password":"pw","dbClusterIdentifier":"prod","proxy":true}`,
+		"plain": `hunter2`,
+	}))
+
+	cred, extras, err := GetCredentialSecretWithExtras(nil, "db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cred.Host != "db.local" || cred.Port != 5432 || cred.Username != "admin" {
+		t.Errorf("cred = %+v", cred.Redacted())
+	}
+	want := map[string]json.RawMessage{
+		"dbClusterIdentifier": json.RawMessage(`"prod"`),
+		"proxy":               json.RawMessage(`true`),
+	}
+	if !reflect.DeepEqual(extras, want) {
+		t.Errorf("extras = %s, want %s", extras, want)
+	}
+
+	for _, name := range []string{"plain", "missing"} {
+		if _, _, err := GetCredentialSecretWithExtras(nil, name); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
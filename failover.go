@@ -0,0 +1,67 @@
+package awssecret
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/pkg/errors"
+)
+
+// GetStringSecretFromRegions retrieves the named secret from AWS Secrets
+// Manager, trying each region in order until one responds. This suits
+// secrets replicated to several regions: the next region is only tried when
+// the previous one is unreachable, throttling or failing with a server
+// error. Any other error, such as ResourceNotFoundException, is returned
+// immediately.
+//
+func GetStringSecretFromRegions(sess *session.Session, secretName string, regions []string) (secret string, err error) {
+	return GetStringSecretFromRegionsWithContext(context.Background(), sess, secretName, regions)
+}
+
+// GetStringSecretFromRegionsWithContext is the same as
+// GetStringSecretFromRegions, but uses the supplied context for the
+// underlying AWS requests.
+//
+func GetStringSecretFromRegionsWithContext(ctx context.Context, sess *session.Session, secretName string, regions []string) (secret string, err error) {
+	return getStringSecretFromRegions(ctx, secretName, regions, func(region string) (SecretsManagerAPI, error) {
+		return NewSecretsManagerClientWithOptions(sess, ClientOptions{Region: region})
+	})
+}
+
+// getStringSecretFromRegions implements GetStringSecretFromRegionsWithContext
+// using clientFor to build the client for each region.
+//
+func getStringSecretFromRegions(ctx context.Context, secretName string, regions []string, clientFor func(region string) (SecretsManagerAPI, error)) (secret string, err error) {
+	if len(regions) == 0 {
+		return "", errors.New("No regions to retrieve the secret from")
+	}
+
+	for _, region := range regions {
+		var client SecretsManagerAPI
+		client, err = clientFor(region)
+		if err != nil {
+			return "", err
+		}
+
+		secret, err = GetStringSecretWithClientContext(ctx, client, secretName)
+		if err == nil || !isRegionFailure(err) {
+			return secret, err
+		}
+	}
+
+	return "", errors.Wrapf(err, "Failed to get secret %q from regions %s", secretName, strings.Join(regions, ", "))
+}
+
+// isRegionFailure reports whether err suggests the region itself is
+// unavailable, rather than a problem with the request or the secret.
+//
+func isRegionFailure(err error) bool {
+	var aerr awserr.Error
+	if !errors.As(err, &aerr) {
+		return false
+	}
+	return aerr.Code() == request.ErrCodeRequestError || isRetryable(aerr)
+}
@@ -0,0 +1,90 @@
+package awssecret
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+// newRegionsSession returns a session which sends each region's requests to
+// the server in urls. Regions missing from urls resolve to a closed server,
+// so requests to them fail as if the region were unreachable.
+func newRegionsSession(t *testing.T, urls map[string]string) *session.Session {
+	t.Helper()
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	resolver := endpoints.ResolverFunc(func(service, region string, opts ...func(*endpoints.Options)) (endpoints.ResolvedEndpoint, error) {
+		url, ok := urls[region]
+		if !ok {
+			url = closed.URL
+		}
+		return endpoints.ResolvedEndpoint{URL: url, SigningRegion: region}, nil
+	})
+	return testSession(t).Copy(aws.NewConfig().WithEndpointResolver(resolver).WithMaxRetries(0))
+}
+
+func TestGetStringSecretFromRegions(t *testing.T) {
+	serve := func(value string) string {
+		url, _ := newSecretsManagerServer(t, func(req smRequest) (int, interface{}) {
+			return http.StatusOK, map[string]string{"Name": "db", "SecretString": value}
+		})
+		return url
+	}
+	notFound, _ := newSecretsManagerServer(t, func(req smRequest) (int, interface{}) {
+		return http.StatusBadRequest, map[string]string{"__type": "ResourceNotFoundException", "message": "not found"}
+	})
+	sess := newRegionsSession(t, map[string]string{
+		"us-east-1": serve("east"),
+		"us-west-2": serve("west"),
+		"eu-west-1": notFound,
+	})
+
+	tests := []struct {
+		name    string
+		regions []string
+		want    string
+		wantErr bool
+	}{
+		{"first region succeeds", []string{"us-east-1", "us-west-2"}, "east", false},
+		{"fails over when a region is unreachable", []string{"ap-south-1", "us-west-2"}, "west", false},
+		{"does not fail over on not found", []string{"eu-west-1", "us-west-2"}, "", true},
+		{"all regions fail", []string{"ap-south-1", "ap-northeast-1"}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret, err := GetStringSecretFromRegions(sess, "db", tt.regions)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if secret != tt.want {
+				t.Errorf("secret = %q, want %q", secret, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetStringSecretFromRegionsAllFailReturnsLastError(t *testing.T) {
+	_, err := getStringSecretFromRegions(context.Background(), "db", []string{"a", "b"}, func(region string) (SecretsManagerAPI, error) {
+		return newErrorStub(awserr.New(request.ErrCodeRequestError, "down in "+region, nil)), nil
+	})
+	var serr *SecretError
+	if !errors.As(err, &serr) || serr.Message != "down in b" {
+		t.Fatalf("err = %v, want the error from the last region", err)
+	}
+}
+
+func TestGetStringSecretFromRegionsNoRegions(t *testing.T) {
+	_, err := GetStringSecretFromRegions(testSession(t), "db", nil)
+	if err == nil {
+		t.Fatal("expected an error when no regions are given")
+	}
+}
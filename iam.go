@@ -0,0 +1,68 @@
+package awssecret
+
+import (
+	"context"
+	"net"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/rds/rdsutils"
+	"github.com/pkg/errors"
+)
+
+// buildAuthToken is rdsutils.BuildAuthToken, replaceable in tests.
+var buildAuthToken = rdsutils.BuildAuthToken
+
+// defaultPostgresPort is used to sign IAM auth tokens for secrets which
+// don't specify a port.
+const defaultPostgresPort = 5432
+
+// GetIAMAuthPostgresDSN retrieves the named secret from AWS Secrets Manager
+// and converts it into a Postgres-compatible DSN which authenticates with an
+// RDS IAM auth token instead of a stored password. The secret needs host,
+// username and dbname (and optionally port); the token is generated from
+// the session's credentials and region. Tokens expire after 15 minutes, so
+// the DSN should be used to connect promptly. Since RDS requires SSL for IAM
+// authentication, sslmode defaults to require.
+//
+func GetIAMAuthPostgresDSN(sess *session.Session, secretName string) (dsnStr string, err error) {
+	return GetIAMAuthPostgresDSNWithContext(context.Background(), sess, secretName)
+}
+
+// GetIAMAuthPostgresDSNWithContext is the same as GetIAMAuthPostgresDSN, but
+// uses the supplied context for the underlying AWS request.
+//
+func GetIAMAuthPostgresDSNWithContext(ctx context.Context, sess *session.Session, secretName string) (dsnStr string, err error) {
+	sess, err = sessionOrDefault(sess)
+	if err != nil {
+		return "", err
+	}
+
+	str, err := GetStringSecretWithContext(ctx, sess, secretName)
+	if err != nil {
+		return "", errors.Wrapf(err, "Couldn't build DSN. Failed to retrieve secret")
+	}
+
+	d := dsn{}
+	err = Unmarshal([]byte(str), &d)
+	if err != nil {
+		return "", errors.Wrapf(err, "Couldn't build DSN. Failed to decode JSON")
+	}
+
+	port := d.Port
+	if port == 0 {
+		port = defaultPostgresPort
+	}
+	endpoint := net.JoinHostPort(d.Host, strconv.Itoa(port))
+
+	d.Password, err = buildAuthToken(endpoint, aws.StringValue(sess.Config.Region), d.Username, sess.Config.Credentials)
+	if err != nil {
+		return "", errors.Wrapf(err, "Couldn't build DSN. Failed to generate IAM auth token")
+	}
+	if d.SSLMode == "" {
+		d.SSLMode = "require"
+	}
+
+	return d.postgres(), nil
+}
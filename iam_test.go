@@ -0,0 +1,100 @@
+package awssecret
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+)
+
+// fakeAuthToken replaces buildAuthToken, recording the endpoint, region and
+// user each token is requested for.
+func fakeAuthToken(t *testing.T, err error) *[]string {
+	t.Helper()
+	var requested []string
+	previous := buildAuthToken
+	buildAuthToken = func(endpoint, region, user string, creds *credentials.Credentials) (string, error) {
+		requested = append(requested, endpoint+" "+region+" "+user)
+		if err != nil {
+			return "", err
+		}
+		return "token-for-" + user, nil
+	}
+	t.Cleanup(func() { buildAuthToken = previous })
+	return &requested
+}
+
+func TestGetIAMAuthPostgresDSN(t *testing.T) {
+	sess := newStringSecretsSession(t, map[string]string{
+		"default-port": `{"host":"db.example.com","username":"app","dbname":"prod"}`,
+		"custom-port":  `{"host":"db.example.com","port":6543,"username":"app","dbname":"prod"}`,
+		"sslmode":      `{"host":"db.example.com","username":"app","dbname":"prod","sslmode":"verify-full"}`,
+		"password":     `{"host":"db.example.com","username":"app","dbname":"prod","password":"stored"}`,
+	})
+
+	tests := []struct {
+		secret       string
+		wantEndpoint string
+		wantDSN      []string
+	}{
+		{"default-port", "db.example.com:5432", []string{"password=token-for-app", "sslmode=require"}},
+		{"custom-port", "db.example.com:6543", []string{"port=6543", "password=token-for-app"}},
+		{"sslmode", "db.example.com:5432", []string{"sslmode=verify-full"}},
+		{"password", "db.example.com:5432", []string{"password=token-for-app"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.secret, func(t *testing.T) {
+			requested := fakeAuthToken(t, nil)
+
+			got, err := GetIAMAuthPostgresDSN(sess, tt.secret)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.wantDSN {
+				if !strings.Contains(got, want) {
+					t.Errorf("DSN %q doesn't contain %q", got, want)
+				}
+			}
+			if strings.Contains(got, "stored") {
+				t.Errorf("DSN %q contains the stored password", got)
+			}
+			want := tt.wantEndpoint + " us-east-1 app"
+			if len(*requested) != 1 || (*requested)[0] != want {
+				t.Errorf("tokens requested = %q, want [%q]", *requested, want)
+			}
+		})
+	}
+}
+
+func TestGetIAMAuthPostgresDSNErrors(t *testing.T) {
+	sess := newStringSecretsSession(t, map[string]string{
+		"db":     `{"host":"db.example.com","username":"app","dbname":"prod"}`,
+		"broken": `{"host":`,
+	})
+	tokenErr := errors.New("no credentials")
+
+	tests := []struct {
+		name     string
+		secret   string
+		tokenErr error
+		wantErr  error
+	}{
+		{"missing secret", "missing", nil, ErrSecretNotFound},
+		{"invalid JSON", "broken", nil, nil},
+		{"token failure", "db", tokenErr, tokenErr},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeAuthToken(t, tt.tokenErr)
+
+			_, err := GetIAMAuthPostgresDSN(sess, tt.secret)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
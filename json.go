@@ -0,0 +1,421 @@
+package awssecret
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/pkg/errors"
+)
+
+// Unmarshal decodes JSON secrets throughout the package. It defaults to
+// encoding/json's Unmarshal, and can be replaced with a compatible decoder
+// such as jsoniter or sonic in high-throughput services. Set it before
+// reading any secrets. Decoding which relies on encoding/json.Decoder
+// features (JSONOptions.StrictJSON, and preserving number precision) still
+// uses encoding/json.
+var Unmarshal = json.Unmarshal
+
+// GetJSONSecret retrieves the named secret from AWS Secrets Manager and
+// JSON-decodes it into a new value of type T. Any struct with the appropriate
+// json tags can be used.
+//
+func GetJSONSecret[T any](sess *session.Session, secretName string) (v *T, err error) {
+	return GetJSONSecretWithContext[T](context.Background(), sess, secretName)
+}
+
+// GetJSONSecretWithContext is the same as GetJSONSecret, but uses the
+// supplied context for the underlying AWS request.
+//
+func GetJSONSecretWithContext[T any](ctx context.Context, sess *session.Session, secretName string) (v *T, err error) {
+	return getJSONSecret[T](ctx, clientFor(sess), secretName, JSONOptions{})
+}
+
+// JSONOptions controls how GetJSONSecretWithOptions decodes a secret.
+//
+type JSONOptions struct {
+	// StrictJSON returns an error if the secret contains fields which
+	// don't exist on the destination struct, rather than ignoring them.
+	StrictJSON bool
+
+	// Transform, when set, is applied to the secret's raw value before it
+	// is decoded, as with GetOptions.Transform.
+	Transform func([]byte) ([]byte, error)
+
+	// RequireObject returns an error unless the secret is a JSON object
+	// with at least one key, catching secrets accidentally stored as "{}",
+	// an array or a plain string.
+	RequireObject bool
+}
+
+// GetJSONSecretWithOptions is the same as GetJSONSecret, but decodes the
+// secret according to opts. For example, GetJSONSecretWithOptions[Credential]
+// with StrictJSON set catches misspelled field names in a credential.
+//
+func GetJSONSecretWithOptions[T any](sess *session.Session, secretName string, opts JSONOptions) (v *T, err error) {
+	return GetJSONSecretWithOptionsContext[T](context.Background(), sess, secretName, opts)
+}
+
+// GetJSONSecretWithOptionsContext is the same as GetJSONSecretWithOptions,
+// but uses the supplied context for the underlying AWS request.
+//
+func GetJSONSecretWithOptionsContext[T any](ctx context.Context, sess *session.Session, secretName string, opts JSONOptions) (v *T, err error) {
+	return getJSONSecret[T](ctx, clientFor(sess), secretName, opts)
+}
+
+func getJSONSecret[T any](ctx context.Context, c *Client, secretName string, opts JSONOptions) (v *T, err error) {
+	return getJSONSecretAs[T](ctx, c, secretName, opts, "Couldn't decode secret")
+}
+
+// getJSONSecretAs implements getJSONSecret, starting its error messages
+// with failure so that typed wrappers such as GetCredentialSecret can keep
+// their own wording.
+//
+func getJSONSecretAs[T any](ctx context.Context, c *Client, secretName string, opts JSONOptions, failure string) (v *T, err error) {
+	var data []byte
+	v = new(T)
+	if opts.Transform != nil {
+		data, err = transformSecret(ctx, c, secretName, opts.Transform)
+	} else {
+		var secret string
+		secret, err = c.GetStringWithContext(ctx, secretName)
+		data = []byte(secret)
+	}
+	if err != nil {
+		return v, errors.Wrapf(err, "%s. Failed to retrieve secret.", failure)
+	}
+
+	if opts.RequireObject {
+		err = ValidateJSONObject(data)
+		if err != nil {
+			return v, errors.Wrapf(err, "%s %q", failure, secretName)
+		}
+	}
+
+	err = decodeJSON(data, v, opts)
+	if err != nil {
+		return v, errors.Wrapf(err, "%s. Failed to decode JSON.", failure)
+	}
+
+	return v, nil
+}
+
+// ValidateJSONObject returns a descriptive error unless data is a JSON
+// object with at least one key.
+//
+func ValidateJSONObject(data []byte) error {
+	var obj map[string]json.RawMessage
+	err := Unmarshal(data, &obj)
+	if err == nil && obj == nil {
+		return errors.New("Secret is null, not a JSON object")
+	}
+	if err == nil && len(obj) == 0 {
+		return errors.New("Secret is an empty JSON object")
+	}
+	if err == nil {
+		return nil
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case !json.Valid(trimmed):
+		return errors.New("Secret is not JSON")
+	case len(trimmed) > 0 && trimmed[0] == '[':
+		return errors.New("Secret is a JSON array, not an object")
+	default:
+		return errors.New("Secret is a JSON scalar, not an object")
+	}
+}
+
+// decodeJSON unmarshals data into v, rejecting unknown fields if
+// opts.StrictJSON is set.
+//
+func decodeJSON(data []byte, v interface{}, opts JSONOptions) error {
+	if !opts.StrictJSON {
+		return Unmarshal(data, v)
+	}
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.DisallowUnknownFields()
+	return d.Decode(v)
+}
+
+// GetRawJSONSecret retrieves the named secret from AWS Secrets Manager and
+// returns its undecoded JSON, after checking that it is valid. This is an
+// escape hatch for fields the package's structs don't model: decode the
+// result with json.Unmarshal into any type, or use GetJSONSecret with a
+// struct of your own when the shape is known in advance.
+//
+func GetRawJSONSecret(sess *session.Session, secretName string) (raw json.RawMessage, err error) {
+	return GetRawJSONSecretWithContext(context.Background(), sess, secretName)
+}
+
+// GetRawJSONSecretWithContext is the same as GetRawJSONSecret, but uses the
+// supplied context for the underlying AWS request.
+//
+func GetRawJSONSecretWithContext(ctx context.Context, sess *session.Session, secretName string) (raw json.RawMessage, err error) {
+	secret, err := GetStringSecretWithContext(ctx, sess, secretName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Couldn't decode secret. Failed to retrieve secret.")
+	}
+
+	if !json.Valid([]byte(secret)) {
+		return nil, errors.Errorf("Couldn't decode secret. Secret %q is not valid JSON.", secretName)
+	}
+
+	return json.RawMessage(secret), nil
+}
+
+// IsJSONSecret retrieves the named secret from AWS Secrets Manager and
+// reports whether its value is valid JSON (an object, an array or a JSON
+// scalar), for callers which fall back to treating it as a plain string.
+//
+func IsJSONSecret(sess *session.Session, secretName string) (isJSON bool, err error) {
+	return IsJSONSecretWithContext(context.Background(), sess, secretName)
+}
+
+// IsJSONSecretWithContext is the same as IsJSONSecret, but uses the
+// supplied context for the underlying AWS request.
+//
+func IsJSONSecretWithContext(ctx context.Context, sess *session.Session, secretName string) (isJSON bool, err error) {
+	secret, err := GetStringSecretWithContext(ctx, sess, secretName)
+	if err != nil {
+		return false, err
+	}
+	return json.Valid([]byte(secret)), nil
+}
+
+// ValidateJSONSecret checks that the named secret exists in AWS Secrets
+// Manager and that it JSON-decodes into a T, returning a descriptive error
+// if not. The value itself is discarded, which makes this suitable for
+// pre-deploy checks.
+//
+func ValidateJSONSecret[T any](sess *session.Session, secretName string) error {
+	return ValidateJSONSecretWithContext[T](context.Background(), sess, secretName)
+}
+
+// ValidateJSONSecretWithContext is the same as ValidateJSONSecret, but uses
+// the supplied context for the underlying AWS request.
+//
+func ValidateJSONSecretWithContext[T any](ctx context.Context, sess *session.Session, secretName string) error {
+	_, err := GetJSONSecretWithContext[T](ctx, sess, secretName)
+	if err != nil {
+		return errors.Wrapf(err, "Secret %q is not valid", secretName)
+	}
+	return nil
+}
+
+// GetJSONArraySecret retrieves the named secret from AWS Secrets Manager and
+// JSON-decodes it into a slice of T, for secrets which hold a JSON array
+// (e.g. a list of API keys). An error is returned if the secret holds a
+// JSON object instead.
+//
+func GetJSONArraySecret[T any](sess *session.Session, secretName string) (v []T, err error) {
+	return GetJSONArraySecretWithContext[T](context.Background(), sess, secretName)
+}
+
+// GetJSONArraySecretWithContext is the same as GetJSONArraySecret, but uses
+// the supplied context for the underlying AWS request.
+//
+func GetJSONArraySecretWithContext[T any](ctx context.Context, sess *session.Session, secretName string) (v []T, err error) {
+	secret, err := GetStringSecretWithContext(ctx, sess, secretName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Couldn't decode secret. Failed to retrieve secret.")
+	}
+
+	if strings.HasPrefix(strings.TrimSpace(secret), "{") {
+		return nil, errors.Errorf("Couldn't decode secret. Secret %q is a JSON object, not an array.", secretName)
+	}
+
+	err = Unmarshal([]byte(secret), &v)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Couldn't decode secret. Failed to decode JSON.")
+	}
+
+	return v, nil
+}
+
+// GetJSONSecretKey retrieves the named secret from AWS Secrets Manager,
+// decodes it as a JSON object and returns the value at key as a string. Keys
+// of nested objects are reached with a dotted path such as "db.host".
+// Numbers and booleans are converted to their JSON text; an error is
+// returned if the key is absent or its value is null, an object or an array.
+//
+func GetJSONSecretKey(sess *session.Session, secretName, key string) (value string, err error) {
+	return GetJSONSecretKeyWithContext(context.Background(), sess, secretName, key)
+}
+
+// GetJSONSecretKeyWithContext is the same as GetJSONSecretKey, but uses the
+// supplied context for the underlying AWS request.
+//
+func GetJSONSecretKeyWithContext(ctx context.Context, sess *session.Session, secretName, key string) (value string, err error) {
+	secret, err := GetStringSecretWithContext(ctx, sess, secretName)
+	if err != nil {
+		return "", errors.Wrapf(err, "Couldn't read key. Failed to retrieve secret.")
+	}
+
+	var obj map[string]interface{}
+	d := json.NewDecoder(bytes.NewReader([]byte(secret)))
+	d.UseNumber()
+	err = d.Decode(&obj)
+	if err != nil {
+		return "", errors.Wrapf(err, "Couldn't read key. Failed to decode JSON.")
+	}
+
+	var v interface{} = obj
+	for _, part := range strings.Split(key, ".") {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return "", errors.Errorf("Key %q not found in secret %q", key, secretName)
+		}
+		v, ok = m[part]
+		if !ok {
+			return "", errors.Errorf("Key %q not found in secret %q", key, secretName)
+		}
+	}
+
+	return scalarString(v, key)
+}
+
+// scalarString converts a decoded JSON scalar to a string.
+//
+func scalarString(v interface{}, key string) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case nil:
+		return "", errors.Errorf("Value of key %q is null", key)
+	default:
+		return "", errors.Errorf("Value of key %q is not a string, number or boolean", key)
+	}
+}
+
+// GetWrappedStringSecret retrieves the named secret from AWS Secrets Manager
+// for secrets stored as a JSON object wrapping a single value, such as
+// {"value": "actual-secret"}, and returns the value at key. If key is
+// empty, the object must have exactly one key, whose value is returned. An
+// error is returned if the key is missing or the secret isn't a JSON
+// object.
+//
+func GetWrappedStringSecret(sess *session.Session, secretName, key string) (value string, err error) {
+	return GetWrappedStringSecretWithContext(context.Background(), sess, secretName, key)
+}
+
+// GetWrappedStringSecretWithContext is the same as GetWrappedStringSecret,
+// but uses the supplied context for the underlying AWS request.
+//
+func GetWrappedStringSecretWithContext(ctx context.Context, sess *session.Session, secretName, key string) (value string, err error) {
+	secret, err := GetStringSecretWithContext(ctx, sess, secretName)
+	if err != nil {
+		return "", errors.Wrapf(err, "Couldn't unwrap secret. Failed to retrieve secret.")
+	}
+
+	var obj map[string]interface{}
+	d := json.NewDecoder(bytes.NewReader([]byte(secret)))
+	d.UseNumber()
+	err = d.Decode(&obj)
+	if err != nil {
+		return "", errors.Wrapf(err, "Couldn't unwrap secret. Secret %q is not a JSON object.", secretName)
+	}
+
+	if key == "" {
+		if len(obj) != 1 {
+			return "", errors.Errorf("Couldn't unwrap secret. Secret %q has %d keys, expected 1.", secretName, len(obj))
+		}
+		for k := range obj {
+			key = k
+		}
+	}
+
+	v, ok := obj[key]
+	if !ok {
+		return "", errors.Errorf("Key %q not found in secret %q", key, secretName)
+	}
+
+	return scalarString(v, key)
+}
+
+// JSONMapOptions controls how GetJSONSecretMapWithOptions flattens a secret.
+//
+type JSONMapOptions struct {
+	// StringifyNested converts nested objects and arrays to their JSON
+	// text rather than returning an error.
+	StringifyNested bool
+}
+
+// GetJSONSecretMap retrieves the named secret from AWS Secrets Manager and
+// decodes its top-level JSON object into a map of strings. Numbers and
+// booleans are converted to their JSON text; nested objects, arrays and
+// nulls cause an error.
+//
+func GetJSONSecretMap(sess *session.Session, secretName string) (m map[string]string, err error) {
+	return GetJSONSecretMapWithOptionsContext(context.Background(), sess, secretName, JSONMapOptions{})
+}
+
+// GetJSONSecretMapWithContext is the same as GetJSONSecretMap, but uses the
+// supplied context for the underlying AWS request.
+//
+func GetJSONSecretMapWithContext(ctx context.Context, sess *session.Session, secretName string) (m map[string]string, err error) {
+	return GetJSONSecretMapWithOptionsContext(ctx, sess, secretName, JSONMapOptions{})
+}
+
+// GetJSONSecretMapWithOptions is the same as GetJSONSecretMap, but flattens
+// the secret according to opts.
+//
+func GetJSONSecretMapWithOptions(sess *session.Session, secretName string, opts JSONMapOptions) (m map[string]string, err error) {
+	return GetJSONSecretMapWithOptionsContext(context.Background(), sess, secretName, opts)
+}
+
+// GetJSONSecretMapWithOptionsContext is the same as
+// GetJSONSecretMapWithOptions, but uses the supplied context for the
+// underlying AWS request.
+//
+func GetJSONSecretMapWithOptionsContext(ctx context.Context, sess *session.Session, secretName string, opts JSONMapOptions) (m map[string]string, err error) {
+	secret, err := GetStringSecretWithContext(ctx, sess, secretName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Couldn't decode secret. Failed to retrieve secret.")
+	}
+
+	var obj map[string]json.RawMessage
+	err = Unmarshal([]byte(secret), &obj)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Couldn't decode secret. Secret %q is not a JSON object.", secretName)
+	}
+
+	m = make(map[string]string, len(obj))
+	for k, raw := range obj {
+		var v interface{}
+		d := json.NewDecoder(bytes.NewReader(raw))
+		d.UseNumber()
+		err = d.Decode(&v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Couldn't decode secret. Failed to decode JSON.")
+		}
+
+		switch v.(type) {
+		case map[string]interface{}, []interface{}:
+			if !opts.StringifyNested {
+				return nil, errors.Errorf("Value of key %q is not a string, number or boolean", k)
+			}
+			var buf bytes.Buffer
+			err = json.Compact(&buf, raw)
+			if err != nil {
+				return nil, errors.Wrapf(err, "Couldn't decode secret. Failed to encode JSON.")
+			}
+			m[k] = buf.String()
+		default:
+			m[k], err = scalarString(v, k)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return m, nil
+}
@@ -0,0 +1,541 @@
+package awssecret
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateJSONObject(t *testing.T) {
+	tests := []struct {
+		data    string
+		wantErr string
+	}{
+		{`{"user":"admin"}`, ""},
+		{` {"user":"admin"} `, ""},
+		{`{}`, "empty JSON object"},
+		{`null`, "null"},
+		{`["a"]`, "JSON array"},
+		{`"hunter2"`, "JSON scalar"},
+		{`42`, "JSON scalar"},
+		{`hunter2`, "not JSON"},
+		{`{"user":`, "not JSON"},
+		{``, "not JSON"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.data, func(t *testing.T) {
+			err := ValidateJSONObject([]byte(tt.data))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// countUnmarshal replaces Unmarshal with a counting wrapper around
+// encoding/json for the rest of the test.
+func countUnmarshal(t *testing.T) *int {
+	t.Helper()
+	var n int
+	previous := Unmarshal
+	Unmarshal = func(data []byte, v interface{}) error {
+		n++
+		return json.Unmarshal(data, v)
+	}
+	t.Cleanup(func() { Unmarshal = previous })
+	return &n
+}
+
+func TestValidateJSONObjectUsesUnmarshal(t *testing.T) {
+	n := countUnmarshal(t)
+	if err := ValidateJSONObject([]byte(`{"user":"admin"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if *n != 1 {
+		t.Errorf("Unmarshal calls = %d, want 1", *n)
+	}
+}
+
+func TestGetJSONSecretWithOptionsRequireObject(t *testing.T) {
+	useDefaultAPI(t, newStringStub(map[string]string{
+		"object": `{"username":"admin"}`,
+		"empty":  `{}`,
+		"array":  `[]`,
+		"null":   `null`,
+		"string": `"admin"`,
+		"plain":  `hunter2`,
+	}))
+
+	tests := []struct {
+		secret  string
+		wantErr bool
+	}{
+		{"object", false},
+		{"empty", true},
+		{"array", true},
+		{"null", true},
+		{"string", true},
+		{"plain", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.secret, func(t *testing.T) {
+			v, err := GetJSONSecretWithOptions[Credential](nil, tt.secret, JSONOptions{RequireObject: true})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && v.Username != "admin" {
+				t.Errorf("Username = %q, want admin", v.Username)
+			}
+		})
+	}
+}
+
+func TestGetJSONSecret(t *testing.T) {
+	type config struct {
+		Host    string `json:"host"`
+		Port    int    `json:"port"`
+		Enabled bool   `json:"enabled"`
+	}
+	useDefaultAPI(t, newStringStub(map[string]string{
+		"config":  `{"host":"db.local","port":5432,"enabled":true,"extra":"ignored"}`,
+		"invalid": `not json`,
+		"wrong":   `{"port":"5432"}`,
+	}))
+
+	tests := []struct {
+		secret  string
+		want    config
+		wantErr bool
+	}{
+		{"config", config{Host: "db.local", Port: 5432, Enabled: true}, false},
+		{"invalid", config{}, true},
+		{"wrong", config{}, true},
+		{"missing", config{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.secret, func(t *testing.T) {
+			got, err := GetJSONSecret[config](nil, tt.secret)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && *got != tt.want {
+				t.Errorf("got %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetJSONSecretKey(t *testing.T) {
+	useDefaultAPI(t, newStringStub(map[string]string{
+		"config": `{"user":"admin","port":5432,"ratio":0.25,"big":12345678901234567890,"tls":true,"none":null,"db":{"host":"db.local","opts":{"ssl":"require"}},"list":[1]}`,
+		"array":  `[1,2]`,
+	}))
+
+	tests := []struct {
+		secret  string
+		key     string
+		want    string
+		wantErr bool
+	}{
+		{"config", "user", "admin", false},
+		{"config", "port", "5432", false},
+		{"config", "ratio", "0.25", false},
+		{"config", "big", "12345678901234567890", false},
+		{"config", "tls", "true", false},
+		{"config", "db.host", "db.local", false},
+		{"config", "db.opts.ssl", "require", false},
+		{"config", "none", "", true},
+		{"config", "db", "", true},
+		{"config", "list", "", true},
+		{"config", "absent", "", true},
+		{"config", "user.name", "", true},
+		{"array", "0", "", true},
+		{"missing", "user", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.secret+"/"+tt.key, func(t *testing.T) {
+			got, err := GetJSONSecretKey(nil, tt.secret, tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetJSONSecretKeyNotFoundQuotesNames(t *testing.T) {
+	useDefaultAPI(t, newStringStub(map[string]string{"prod db": `{"user":"admin"}`}))
+
+	_, err := GetJSONSecretKey(nil, "prod db", "password")
+	if want := `Key "password" not found in secret "prod db"`; err == nil || err.Error() != want {
+		t.Errorf("err = %v, want %s", err, want)
+	}
+}
+
+func TestGetJSONArraySecret(t *testing.T) {
+	useDefaultAPI(t, newStringStub(map[string]string{
+		"keys":    `["a","b"]`,
+		"padded":  ` ["a"] `,
+		"empty":   `[]`,
+		"object":  `{"keys":["a"]}`,
+		"invalid": `[`,
+	}))
+
+	tests := []struct {
+		secret  string
+		want    []string
+		wantErr bool
+	}{
+		{"keys", []string{"a", "b"}, false},
+		{"padded", []string{"a"}, false},
+		{"empty", []string{}, false},
+		{"object", nil, true},
+		{"invalid", nil, true},
+		{"missing", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.secret, func(t *testing.T) {
+			got, err := GetJSONArraySecret[string](nil, tt.secret)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateJSONSecret(t *testing.T) {
+	type creds struct {
+		User     string `json:"user"`
+		Password string `json:"password"`
+	}
+	useDefaultAPI(t, newStringStub(map[string]string{
+		"valid":     `{"user":"admin","password":"hunter2"}`,
+		"malformed": `{"user":"admin","password":"hunter2"`,
+		"wrongtype": `{"user":42}`,
+	}))
+
+	tests := []struct {
+		secret   string
+		wantErr  bool
+		wantIsNF bool
+	}{
+		{"valid", false, false},
+		{"malformed", true, false},
+		{"wrongtype", true, false},
+		{"missing", true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.secret, func(t *testing.T) {
+			err := ValidateJSONSecret[creds](nil, tt.secret)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil {
+				return
+			}
+			if !strings.Contains(err.Error(), tt.secret) {
+				t.Errorf("err %q doesn't name the secret", err)
+			}
+			if strings.Contains(err.Error(), "hunter2") {
+				t.Errorf("err %q contains the secret's value", err)
+			}
+			if got := errors.Is(err, ErrSecretNotFound); got != tt.wantIsNF {
+				t.Errorf("errors.Is(err, ErrSecretNotFound) = %v, want %v", got, tt.wantIsNF)
+			}
+		})
+	}
+}
+
+func TestGetRawJSONSecret(t *testing.T) {
+	useDefaultAPI(t, newStringStub(map[string]string{
+		"object": `{"host":"db.local","dbClusterIdentifier":"prod-cluster"}`,
+		"array":  `["a","b"]`,
+		"scalar": `"hunter2"`,
+		"plain":  `hunter2`,
+	}))
+
+	tests := []struct {
+		secret  string
+		wantErr bool
+	}{
+		{"object", false},
+		{"array", false},
+		{"scalar", false},
+		{"plain", true},
+		{"missing", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.secret, func(t *testing.T) {
+			raw, err := GetRawJSONSecret(nil, tt.secret)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !json.Valid(raw) {
+				t.Errorf("raw %q isn't valid JSON", raw)
+			}
+			encoded, err := json.Marshal(raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var decoded json.RawMessage
+			if err := json.Unmarshal(encoded, &decoded); err != nil {
+				t.Fatal(err)
+			}
+			if !json.Valid(decoded) {
+				t.Errorf("round-tripped %q isn't valid JSON", decoded)
+			}
+		})
+	}
+
+	raw, err := GetRawJSONSecret(nil, "object")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]string
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		t.Fatal(err)
+	}
+	if fields["dbClusterIdentifier"] != "prod-cluster" {
+		t.Errorf("dbClusterIdentifier = %q, want prod-cluster", fields["dbClusterIdentifier"])
+	}
+}
+
+func TestGetJSONSecretWithOptionsStrictJSON(t *testing.T) {
+	useDefaultAPI(t, newStringStub(map[string]string{
+		"credential": `{"username":"admin","password":"hunter2"}`,
+		"typo":       `{"username":"admin","pasword":"hunter2"}`,
+		"api":        `{"baseURL":"https://api.example.com","key":"k","secret":"s"}`,
+		"api-extra":  `{"baseURL":"https://api.example.com","key":"k","secret":"s","region":"eu"}`,
+	}))
+
+	tests := []struct {
+		secret  string
+		strict  bool
+		wantErr bool
+	}{
+		{"credential", false, false},
+		{"credential", true, false},
+		{"typo", false, false},
+		{"typo", true, true},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s strict=%v", tt.secret, tt.strict), func(t *testing.T) {
+			v, err := GetJSONSecretWithOptions[Credential](nil, tt.secret, JSONOptions{StrictJSON: tt.strict})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), "pasword") {
+				t.Errorf("err %q doesn't name the unknown field", err)
+			}
+			if !tt.wantErr && v.Username != "admin" {
+				t.Errorf("Username = %q, want admin", v.Username)
+			}
+		})
+	}
+
+	apiTests := []struct {
+		secret  string
+		strict  bool
+		wantErr bool
+	}{
+		{"api", true, false},
+		{"api-extra", false, false},
+		{"api-extra", true, true},
+	}
+	for _, tt := range apiTests {
+		t.Run(fmt.Sprintf("%s strict=%v", tt.secret, tt.strict), func(t *testing.T) {
+			_, err := GetJSONSecretWithOptions[APICredential](nil, tt.secret, JSONOptions{StrictJSON: tt.strict})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetWrappedStringSecret(t *testing.T) {
+	useDefaultAPI(t, newStringStub(map[string]string{
+		"wrapped": `{"value":"actual-secret"}`,
+		"number":  `{"port":12345678901234567890}`,
+		"bool":    `{"enabled":true}`,
+		"multi":   `{"value":"actual-secret","other":"x"}`,
+		"null":    `{"value":null}`,
+		"nested":  `{"value":{"inner":"x"}}`,
+		"array":   `["actual-secret"]`,
+		"plain":   `actual-secret`,
+	}))
+
+	tests := []struct {
+		name    string
+		secret  string
+		key     string
+		want    string
+		wantErr bool
+	}{
+		{"named key", "wrapped", "value", "actual-secret", false},
+		{"only key", "wrapped", "", "actual-secret", false},
+		{"number", "number", "", "12345678901234567890", false},
+		{"bool", "bool", "enabled", "true", false},
+		{"named key of several", "multi", "other", "x", false},
+		{"several keys", "multi", "", "", true},
+		{"absent key", "wrapped", "password", "", true},
+		{"null", "null", "value", "", true},
+		{"nested", "nested", "value", "", true},
+		{"array", "array", "", "", true},
+		{"not JSON", "plain", "value", "", true},
+		{"missing secret", "missing", "value", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetWrappedStringSecret(nil, tt.secret, tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetJSONSecretMap(t *testing.T) {
+	useDefaultAPI(t, newStringStub(map[string]string{
+		"mixed":  `{"host":"db.local","port":5432,"ratio":0.25,"big":12345678901234567890,"tls":true,"debug":false}`,
+		"nested": `{"host":"db.local","pool":{ "max": 10 },"hosts":["a", "b"]}`,
+		"null":   `{"host":null}`,
+		"array":  `["db.local"]`,
+	}))
+
+	tests := []struct {
+		name    string
+		secret  string
+		opts    JSONMapOptions
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			"mixed types", "mixed", JSONMapOptions{},
+			map[string]string{"host": "db.local", "port": "5432", "ratio": "0.25", "big": "12345678901234567890", "tls": "true", "debug": "false"},
+			false,
+		},
+		{"nested rejected", "nested", JSONMapOptions{}, nil, true},
+		{
+			"nested stringified", "nested", JSONMapOptions{StringifyNested: true},
+			map[string]string{"host": "db.local", "pool": `{"max":10}`, "hosts": `["a","b"]`},
+			false,
+		},
+		{"null", "null", JSONMapOptions{StringifyNested: true}, nil, true},
+		{"not an object", "array", JSONMapOptions{}, nil, true},
+		{"missing", "missing", JSONMapOptions{}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetJSONSecretMapWithOptions(nil, tt.secret, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetJSONSecretWithOptionsTransform(t *testing.T) {
+	reverse := func(b []byte) ([]byte, error) {
+		out := make([]byte, len(b))
+		for i := range b {
+			out[len(b)-1-i] = b[i]
+		}
+		return out, nil
+	}
+	useDefaultAPI(t, newStringStub(map[string]string{
+		"reversed": `}"nimda":"emanresu"{`,
+		"plain":    `{"username":"admin"}`,
+	}))
+
+	tests := []struct {
+		name      string
+		secret    string
+		transform func([]byte) ([]byte, error)
+		want      string
+		wantErr   bool
+	}{
+		{"transformed before decoding", "reversed", reverse, "admin", false},
+		{"untransformed", "reversed", nil, "", true},
+		{"identity", "plain", func(b []byte) ([]byte, error) { return b, nil }, "admin", false},
+		{"failing transform", "plain", func([]byte) ([]byte, error) { return nil, errors.New("bad key") }, "", true},
+		{"missing", "missing", reverse, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cred, err := GetJSONSecretWithOptions[Credential](nil, tt.secret, JSONOptions{Transform: tt.transform})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && cred.Username != tt.want {
+				t.Errorf("Username = %q, want %q", cred.Username, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsJSONSecret(t *testing.T) {
+	useDefaultAPI(t, newStringStub(map[string]string{
+		"object":    `{"user":"admin"}`,
+		"array":     `[1,2]`,
+		"string":    `"hunter2"`,
+		"number":    `42`,
+		"padded":    " {\"user\":\"admin\"}\n",
+		"plain":     `hunter2`,
+		"dsn":       `host=db.local dbname=app`,
+		"truncated": `{"user":`,
+		"empty":     ``,
+	}))
+
+	tests := []struct {
+		secret string
+		want   bool
+	}{
+		{"object", true},
+		{"array", true},
+		{"string", true},
+		{"number", true},
+		{"padded", true},
+		{"plain", false},
+		{"dsn", false},
+		{"truncated", false},
+		{"empty", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.secret, func(t *testing.T) {
+			got, err := IsJSONSecret(nil, tt.secret)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := IsJSONSecret(nil, "missing"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("err = %v, want ErrSecretNotFound", err)
+	}
+}
@@ -0,0 +1,193 @@
+package awssecret
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/pkg/errors"
+)
+
+// ListSecretsAPI is the subset of the AWS Secrets Manager client used to
+// enumerate secrets.
+//
+type ListSecretsAPI interface {
+	ListSecretsWithContext(context.Context, *secretsmanager.ListSecretsInput, ...request.Option) (*secretsmanager.ListSecretsOutput, error)
+}
+
+// ListSecretNames returns the names of every secret in AWS Secrets Manager
+// whose name starts with prefix. An empty prefix lists all secrets. Only
+// metadata is read; no secret values are retrieved.
+//
+func ListSecretNames(sess *session.Session, prefix string) (names []string, err error) {
+	return ListSecretNamesWithTagsContext(context.Background(), sess, prefix, nil)
+}
+
+// ListSecretNamesWithContext is the same as ListSecretNames, but uses the
+// supplied context for the underlying AWS requests.
+//
+func ListSecretNamesWithContext(ctx context.Context, sess *session.Session, prefix string) (names []string, err error) {
+	return ListSecretNamesWithTagsContext(ctx, sess, prefix, nil)
+}
+
+// ListSecretNamesWithTags is the same as ListSecretNames, but only returns
+// secrets which carry every one of the supplied tag key/value pairs.
+//
+func ListSecretNamesWithTags(sess *session.Session, prefix string, tags map[string]string) (names []string, err error) {
+	return ListSecretNamesWithTagsContext(context.Background(), sess, prefix, tags)
+}
+
+// ListSecretNamesWithTagsContext is the same as ListSecretNamesWithTags, but
+// uses the supplied context for the underlying AWS requests.
+//
+func ListSecretNamesWithTagsContext(ctx context.Context, sess *session.Session, prefix string, tags map[string]string) (names []string, err error) {
+	svc, err := newService(sess, ClientOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return ListSecretNamesWithClientContext(ctx, svc, prefix, tags)
+}
+
+// ListSecretNamesWithClientContext is the same as
+// ListSecretNamesWithTagsContext, but uses the supplied client.
+//
+func ListSecretNamesWithClientContext(ctx context.Context, client ListSecretsAPI, prefix string, tags map[string]string) (names []string, err error) {
+	entries, err := listSecrets(ctx, client, prefix, tags)
+	if err != nil {
+		return nil, err
+	}
+
+	names = make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, aws.StringValue(entry.Name))
+	}
+	return names, nil
+}
+
+// TaggedSecretsManagerAPI is the subset of the AWS Secrets Manager client
+// needed to find a secret by its tags and read it.
+//
+type TaggedSecretsManagerAPI interface {
+	SecretsManagerAPI
+	ListSecretsAPI
+}
+
+// GetStringSecretByTags finds the one secret in AWS Secrets Manager which
+// carries every one of the supplied tag key/value pairs and returns its
+// value in raw form. An error matching ErrSecretNotFound is returned if no
+// secret matches, and one matching ErrMultipleSecrets if several do.
+//
+func GetStringSecretByTags(sess *session.Session, tags map[string]string) (secret string, err error) {
+	return GetStringSecretByTagsWithContext(context.Background(), sess, tags)
+}
+
+// GetStringSecretByTagsWithContext is the same as GetStringSecretByTags, but
+// uses the supplied context for the underlying AWS requests.
+//
+func GetStringSecretByTagsWithContext(ctx context.Context, sess *session.Session, tags map[string]string) (secret string, err error) {
+	svc, err := newService(sess, ClientOptions{})
+	if err != nil {
+		return "", err
+	}
+	return GetStringSecretByTagsWithClientContext(ctx, svc, tags)
+}
+
+// GetStringSecretByTagsWithClientContext is the same as
+// GetStringSecretByTagsWithContext, but uses the supplied client.
+//
+func GetStringSecretByTagsWithClientContext(ctx context.Context, client TaggedSecretsManagerAPI, tags map[string]string) (secret string, err error) {
+	if len(tags) == 0 {
+		return "", errors.New("Couldn't find secret. No tags given.")
+	}
+
+	entries, err := listSecrets(ctx, client, "", tags)
+	if err != nil {
+		return "", err
+	}
+
+	switch len(entries) {
+	case 0:
+		return "", errors.Wrapf(ErrSecretNotFound, "No secret has tags %v", tags)
+	case 1:
+		return getStringSecret(ctx, client, newGetSecretValueInput(aws.StringValue(entries[0].ARN), "", ""))
+	default:
+		names := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			names = append(names, aws.StringValue(entry.Name))
+		}
+		return "", errors.Wrapf(ErrMultipleSecrets, "Secrets with tags %v: %s", tags, strings.Join(names, ", "))
+	}
+}
+
+// listSecrets pages through ListSecrets, returning the entries whose names
+// start with prefix and which carry all of the tags.
+//
+func listSecrets(ctx context.Context, client ListSecretsAPI, prefix string, tags map[string]string) (entries []*secretsmanager.SecretListEntry, err error) {
+	input := &secretsmanager.ListSecretsInput{}
+	if prefix != "" {
+		input.Filters = append(input.Filters, &secretsmanager.Filter{
+			Key:    aws.String(secretsmanager.FilterNameStringTypeName),
+			Values: aws.StringSlice([]string{prefix}),
+		})
+	}
+
+	// Tag keys and values are filtered independently by AWS, so the pairs
+	// are also checked against each result below.
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		input.Filters = append(input.Filters,
+			&secretsmanager.Filter{
+				Key:    aws.String(secretsmanager.FilterNameStringTypeTagKey),
+				Values: aws.StringSlice([]string{k}),
+			},
+			&secretsmanager.Filter{
+				Key:    aws.String(secretsmanager.FilterNameStringTypeTagValue),
+				Values: aws.StringSlice([]string{tags[k]}),
+			},
+		)
+	}
+
+	for {
+		output, err := client.ListSecretsWithContext(ctx, input)
+		if err != nil {
+			return nil, errors.Wrapf(mapAWSError("", err), "Failed to list secrets in AWS Secrets Manager")
+		}
+
+		for _, entry := range output.SecretList {
+			if strings.HasPrefix(aws.StringValue(entry.Name), prefix) && hasTags(entry.Tags, tags) {
+				entries = append(entries, entry)
+			}
+		}
+
+		if aws.StringValue(output.NextToken) == "" {
+			return entries, nil
+		}
+		input.NextToken = output.NextToken
+	}
+}
+
+// hasTags reports whether have includes every key/value pair in want.
+//
+func hasTags(have []*secretsmanager.Tag, want map[string]string) bool {
+	for k, v := range want {
+		found := false
+		for _, tag := range have {
+			if aws.StringValue(tag.Key) == k && aws.StringValue(tag.Value) == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
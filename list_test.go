@@ -0,0 +1,187 @@
+package awssecret
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+)
+
+// listStub is a hand-rolled ListSecretsAPI which returns pages in order,
+// linked by NextToken. Like AWS's own filters, it doesn't check that
+// tag keys and values belong to the same tag. Every input is recorded.
+type listStub struct {
+	pages  [][]*secretsmanager.SecretListEntry
+	err    error
+	inputs []*secretsmanager.ListSecretsInput
+}
+
+func (s *listStub) ListSecretsWithContext(ctx context.Context, input *secretsmanager.ListSecretsInput, opts ...request.Option) (*secretsmanager.ListSecretsOutput, error) {
+	copied := *input
+	s.inputs = append(s.inputs, &copied)
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	page := 0
+	if input.NextToken != nil {
+		page, _ = strconv.Atoi(*input.NextToken)
+	}
+	output := &secretsmanager.ListSecretsOutput{SecretList: s.pages[page]}
+	if page+1 < len(s.pages) {
+		output.NextToken = aws.String(strconv.Itoa(page + 1))
+	}
+	return output, nil
+}
+
+func listEntry(name string, tags ...string) *secretsmanager.SecretListEntry {
+	entry := &secretsmanager.SecretListEntry{
+		Name: aws.String(name),
+		ARN:  aws.String("arn:aws:secretsmanager:us-east-1:123456789012:secret:" + name + "-AbCdEf"),
+	}
+	for i := 0; i+1 < len(tags); i += 2 {
+		entry.Tags = append(entry.Tags, &secretsmanager.Tag{Key: aws.String(tags[i]), Value: aws.String(tags[i+1])})
+	}
+	return entry
+}
+
+func TestListSecretNames(t *testing.T) {
+	pages := [][]*secretsmanager.SecretListEntry{
+		{listEntry("prod/db", "env", "prod", "team", "data"), listEntry("prod/api", "env", "prod")},
+		{},
+		{listEntry("staging/db", "env", "staging"), listEntry("prod/cache", "env", "staging", "team", "prod")},
+	}
+
+	tests := []struct {
+		name   string
+		prefix string
+		tags   map[string]string
+		want   []string
+	}{
+		{"everything", "", nil, []string{"prod/db", "prod/api", "staging/db", "prod/cache"}},
+		{"prefix", "prod/", nil, []string{"prod/db", "prod/api", "prod/cache"}},
+		{"no match", "dev/", nil, []string{}},
+		{"tag", "", map[string]string{"env": "prod"}, []string{"prod/db", "prod/api"}},
+		{"tags", "", map[string]string{"env": "prod", "team": "data"}, []string{"prod/db"}},
+		{"mismatched pair", "", map[string]string{"team": "staging"}, []string{}},
+		{"prefix and tag", "prod/", map[string]string{"env": "staging"}, []string{"prod/cache"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &listStub{pages: pages}
+			got, err := ListSecretNamesWithClientContext(context.Background(), stub, tt.prefix, tt.tags)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			if len(stub.inputs) != len(pages) {
+				t.Errorf("ListSecrets called %d times, want %d", len(stub.inputs), len(pages))
+			}
+		})
+	}
+}
+
+func TestListSecretNamesFilters(t *testing.T) {
+	stub := &listStub{pages: [][]*secretsmanager.SecretListEntry{{}}}
+	_, err := ListSecretNamesWithClientContext(context.Background(), stub, "prod/", map[string]string{"team": "data", "env": "prod"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []*secretsmanager.Filter{
+		{Key: aws.String("name"), Values: aws.StringSlice([]string{"prod/"})},
+		{Key: aws.String("tag-key"), Values: aws.StringSlice([]string{"env"})},
+		{Key: aws.String("tag-value"), Values: aws.StringSlice([]string{"prod"})},
+		{Key: aws.String("tag-key"), Values: aws.StringSlice([]string{"team"})},
+		{Key: aws.String("tag-value"), Values: aws.StringSlice([]string{"data"})},
+	}
+	if got := stub.inputs[0].Filters; !reflect.DeepEqual(got, want) {
+		t.Errorf("filters = %v, want %v", got, want)
+	}
+}
+
+func TestListSecretNamesError(t *testing.T) {
+	stub := &listStub{err: errors.New("boom")}
+	if _, err := ListSecretNamesWithClientContext(context.Background(), stub, "", nil); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+// taggedStub combines listStub and stubClient into a
+// TaggedSecretsManagerAPI.
+type taggedStub struct {
+	*listStub
+	*stubClient
+}
+
+func TestGetStringSecretByTags(t *testing.T) {
+	pages := [][]*secretsmanager.SecretListEntry{
+		{listEntry("prod/db", "env", "prod", "app", "billing"), listEntry("prod/api", "env", "prod", "app", "api")},
+		{listEntry("staging/db", "env", "staging", "app", "billing")},
+	}
+	values := map[string]string{}
+	for _, page := range pages {
+		for _, entry := range page {
+			values[aws.StringValue(entry.ARN)] = "value of " + aws.StringValue(entry.Name)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		tags    map[string]string
+		want    string
+		wantErr error
+	}{
+		{"one match", map[string]string{"env": "prod", "app": "billing"}, "value of prod/db", nil},
+		{"match on a later page", map[string]string{"env": "staging"}, "value of staging/db", nil},
+		{"several matches", map[string]string{"env": "prod"}, "", ErrMultipleSecrets},
+		{"no match", map[string]string{"env": "dev"}, "", ErrSecretNotFound},
+		{"mismatched pair", map[string]string{"env": "billing"}, "", ErrSecretNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := taggedStub{&listStub{pages: pages}, newStringStub(values)}
+			got, err := GetStringSecretByTagsWithClientContext(context.Background(), stub, tt.tags)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if tt.wantErr != nil && stub.calls() != 0 {
+				t.Errorf("GetSecretValue called %d times, want 0", stub.calls())
+			}
+		})
+	}
+}
+
+func TestGetStringSecretByTagsNamesMatches(t *testing.T) {
+	stub := taggedStub{&listStub{pages: [][]*secretsmanager.SecretListEntry{
+		{listEntry("prod/db", "env", "prod"), listEntry("prod/api", "env", "prod")},
+	}}, newStringStub(nil)}
+
+	_, err := GetStringSecretByTagsWithClientContext(context.Background(), stub, map[string]string{"env": "prod"})
+	for _, want := range []string{"prod/db", "prod/api"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("err = %v, want it to name %q", err, want)
+		}
+	}
+}
+
+func TestGetStringSecretByTagsRequiresTags(t *testing.T) {
+	stub := taggedStub{&listStub{}, newStringStub(nil)}
+	if _, err := GetStringSecretByTagsWithClientContext(context.Background(), stub, nil); err == nil {
+		t.Fatal("expected an error")
+	}
+	if len(stub.listStub.inputs) != 0 {
+		t.Errorf("ListSecrets called %d times, want 0", len(stub.listStub.inputs))
+	}
+}
@@ -0,0 +1,95 @@
+package awssecret
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// FetchEvent describes a single attempt to retrieve a secret value. It
+// never contains the value itself.
+//
+type FetchEvent struct {
+	SecretName string
+	Duration   time.Duration
+	CacheHit   bool
+	Err        error
+
+	// Stale is set when an expired cached value was served because
+	// refreshing it failed (see CacheOptions.ServeStaleOnError). Err is
+	// nil in that case.
+	Stale bool
+}
+
+// Logger receives a FetchEvent after each secret value is retrieved,
+// whether it succeeded or not.
+//
+type Logger interface {
+	LogFetch(FetchEvent)
+}
+
+// LoggerFunc adapts an ordinary function to the Logger interface.
+//
+type LoggerFunc func(FetchEvent)
+
+// LogFetch calls f(e).
+func (f LoggerFunc) LogFetch(e FetchEvent) { f(e) }
+
+type nopLogger struct{}
+
+func (nopLogger) LogFetch(FetchEvent) {}
+
+var (
+	loggerMu sync.RWMutex
+	logger   Logger = nopLogger{}
+)
+
+// SetLogger installs l as the package's Logger. Passing nil restores the
+// default, which discards every event.
+//
+func SetLogger(l Logger) {
+	if l == nil {
+		l = nopLogger{}
+	}
+	loggerMu.Lock()
+	logger = l
+	loggerMu.Unlock()
+}
+
+func currentLogger() Logger {
+	loggerMu.RLock()
+	defer loggerMu.RUnlock()
+	return logger
+}
+
+type fetchEventKey struct{}
+
+// markCacheHit records on the FetchEvent being built for ctx, if any, that
+// the value was served from a cache.
+//
+func markCacheHit(ctx context.Context) {
+	if e, ok := ctx.Value(fetchEventKey{}).(*FetchEvent); ok {
+		e.CacheHit = true
+	}
+}
+
+// markStale records on the FetchEvent being built for ctx, if any, that a
+// stale cached value was served.
+//
+func markStale(ctx context.Context) {
+	if e, ok := ctx.Value(fetchEventKey{}).(*FetchEvent); ok {
+		e.CacheHit = true
+		e.Stale = true
+	}
+}
+
+// MultiLogger returns a Logger which passes each event to all of loggers,
+// e.g. to both log and record metrics.
+//
+func MultiLogger(loggers ...Logger) Logger {
+	return LoggerFunc(func(e FetchEvent) {
+		for _, l := range loggers {
+			l.LogFetch(e)
+		}
+	})
+}
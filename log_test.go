@@ -0,0 +1,92 @@
+package awssecret
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// captureEvents installs a Logger for the rest of the test and returns a
+// function reporting the events it has received.
+func captureEvents(t *testing.T) func() []FetchEvent {
+	t.Helper()
+	var mu sync.Mutex
+	var events []FetchEvent
+	SetLogger(LoggerFunc(func(e FetchEvent) {
+		mu.Lock()
+		events = append(events, e)
+		mu.Unlock()
+	}))
+	t.Cleanup(func() { SetLogger(nil) })
+
+	return func() []FetchEvent {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]FetchEvent(nil), events...)
+	}
+}
+
+func TestLoggerReceivesFetchEvents(t *testing.T) {
+	events := captureEvents(t)
+	stub := newStringStub(map[string]string{"db": "hunter2"})
+	cache, _ := newTestCache(stub, CacheOptions{TTL: time.Minute})
+
+	tests := []struct {
+		name         string
+		api          SecretsManagerAPI
+		secret       string
+		wantErr      bool
+		wantCacheHit bool
+	}{
+		{"success", stub, "db", false, false},
+		{"failure", stub, "missing", true, false},
+		{"cache miss", cache, "db", false, false},
+		{"cache hit", cache, "db", false, true},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			GetStringSecretWithClient(tt.api, tt.secret)
+
+			got := events()
+			if len(got) != i+1 {
+				t.Fatalf("events = %d, want %d", len(got), i+1)
+			}
+			e := got[i]
+			if e.SecretName != tt.secret {
+				t.Errorf("SecretName = %q, want %q", e.SecretName, tt.secret)
+			}
+			if (e.Err != nil) != tt.wantErr {
+				t.Errorf("Err = %v, wantErr %v", e.Err, tt.wantErr)
+			}
+			if e.CacheHit != tt.wantCacheHit {
+				t.Errorf("CacheHit = %v, want %v", e.CacheHit, tt.wantCacheHit)
+			}
+			if e.Duration < 0 {
+				t.Errorf("Duration = %s, want non-negative", e.Duration)
+			}
+		})
+	}
+}
+
+func TestSetLoggerNilRestoresDefault(t *testing.T) {
+	events := captureEvents(t)
+	SetLogger(nil)
+
+	GetStringSecretWithClient(newStringStub(map[string]string{"db": "hunter2"}), "db")
+	if n := len(events()); n != 0 {
+		t.Errorf("events = %d after SetLogger(nil), want 0", n)
+	}
+}
+
+func TestMultiLogger(t *testing.T) {
+	var a, b []string
+	l := MultiLogger(
+		LoggerFunc(func(e FetchEvent) { a = append(a, e.SecretName) }),
+		LoggerFunc(func(e FetchEvent) { b = append(b, e.SecretName) }),
+	)
+	l.LogFetch(FetchEvent{SecretName: "db"})
+
+	if len(a) != 1 || len(b) != 1 || a[0] != "db" || b[0] != "db" {
+		t.Errorf("loggers received %v and %v, want [db] each", a, b)
+	}
+}
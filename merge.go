@@ -0,0 +1,99 @@
+package awssecret
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/pkg/errors"
+)
+
+// MergeOptions controls how GetMergedJSONSecretWithOptions combines
+// secrets.
+//
+type MergeOptions struct {
+	// Deep merges nested objects key by key. Otherwise, a top-level key in
+	// a later secret replaces the whole value from earlier secrets.
+	Deep bool
+}
+
+// GetMergedJSONSecret retrieves each of the named secrets from AWS Secrets
+// Manager in order, merges their JSON objects and decodes the result into a
+// new value of type T. Each top-level key takes its value from the last
+// secret which contains it, so later secrets (e.g. "overrides") win over
+// earlier ones (e.g. "base").
+//
+func GetMergedJSONSecret[T any](sess *session.Session, secretNames ...string) (v *T, err error) {
+	return GetMergedJSONSecretWithOptionsContext[T](context.Background(), sess, MergeOptions{}, secretNames...)
+}
+
+// GetMergedJSONSecretWithContext is the same as GetMergedJSONSecret, but
+// uses the supplied context for the underlying AWS requests.
+//
+func GetMergedJSONSecretWithContext[T any](ctx context.Context, sess *session.Session, secretNames ...string) (v *T, err error) {
+	return GetMergedJSONSecretWithOptionsContext[T](ctx, sess, MergeOptions{}, secretNames...)
+}
+
+// GetMergedJSONSecretWithOptions is the same as GetMergedJSONSecret, but
+// merges the secrets according to opts.
+//
+func GetMergedJSONSecretWithOptions[T any](sess *session.Session, opts MergeOptions, secretNames ...string) (v *T, err error) {
+	return GetMergedJSONSecretWithOptionsContext[T](context.Background(), sess, opts, secretNames...)
+}
+
+// GetMergedJSONSecretWithOptionsContext is the same as
+// GetMergedJSONSecretWithOptions, but uses the supplied context for the
+// underlying AWS requests.
+//
+func GetMergedJSONSecretWithOptionsContext[T any](ctx context.Context, sess *session.Session, opts MergeOptions, secretNames ...string) (v *T, err error) {
+	merged := map[string]interface{}{}
+	for _, name := range secretNames {
+		secret, err := GetStringSecretWithContext(ctx, sess, name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Couldn't merge secrets. Failed to retrieve secret.")
+		}
+
+		// Numbers are kept as json.Number so that large integers survive
+		// the round trip.
+		var obj map[string]interface{}
+		d := json.NewDecoder(strings.NewReader(secret))
+		d.UseNumber()
+		err = d.Decode(&obj)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Couldn't merge secrets. Secret %q is not a JSON object.", name)
+		}
+
+		mergeJSON(merged, obj, opts.Deep)
+	}
+
+	data, err := json.Marshal(merged)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Couldn't merge secrets. Failed to encode JSON.")
+	}
+
+	v = new(T)
+	err = Unmarshal(data, v)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Couldn't merge secrets. Failed to decode JSON.")
+	}
+
+	return v, nil
+}
+
+// mergeJSON copies src's keys over dst's, recursing into objects present in
+// both when deep is set.
+//
+func mergeJSON(dst, src map[string]interface{}, deep bool) {
+	for k, sv := range src {
+		if deep {
+			dm, dok := dst[k].(map[string]interface{})
+			sm, sok := sv.(map[string]interface{})
+			if dok && sok {
+				mergeJSON(dm, sm, deep)
+				continue
+			}
+		}
+		dst[k] = sv
+	}
+}
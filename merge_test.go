@@ -0,0 +1,102 @@
+package awssecret
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetMergedJSONSecret(t *testing.T) {
+	sess := newStringSecretsSession(t, map[string]string{
+		"base":      `{"host":"db.local","port":5432,"pool":{"min":1,"max":10},"debug":false}`,
+		"overrides": `{"host":"db.prod","pool":{"max":50}}`,
+		"local":     `{"debug":true,"id":12345678901234567890}`,
+		"array":     `["not","an","object"]`,
+	})
+
+	type pool struct {
+		Min int `json:"min"`
+		Max int `json:"max"`
+	}
+	type config struct {
+		Host  string `json:"host"`
+		Port  int    `json:"port"`
+		Pool  pool   `json:"pool"`
+		Debug bool   `json:"debug"`
+		ID    uint64 `json:"id"`
+	}
+
+	tests := []struct {
+		name    string
+		secrets []string
+		opts    MergeOptions
+		want    config
+		wantErr bool
+	}{
+		{"one", []string{"base"}, MergeOptions{}, config{Host: "db.local", Port: 5432, Pool: pool{1, 10}}, false},
+		{"two shallow", []string{"base", "overrides"}, MergeOptions{}, config{Host: "db.prod", Port: 5432, Pool: pool{0, 50}}, false},
+		{"two deep", []string{"base", "overrides"}, MergeOptions{Deep: true}, config{Host: "db.prod", Port: 5432, Pool: pool{1, 50}}, false},
+		{"reversed", []string{"overrides", "base"}, MergeOptions{Deep: true}, config{Host: "db.local", Port: 5432, Pool: pool{1, 10}}, false},
+		{"three", []string{"base", "overrides", "local"}, MergeOptions{Deep: true}, config{Host: "db.prod", Port: 5432, Pool: pool{1, 50}, Debug: true, ID: 12345678901234567890}, false},
+		{"none", nil, MergeOptions{}, config{}, false},
+		{"not an object", []string{"base", "array"}, MergeOptions{}, config{}, true},
+		{"missing", []string{"base", "missing"}, MergeOptions{}, config{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetMergedJSONSecretWithOptions[config](sess, tt.opts, tt.secrets...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && *got != tt.want {
+				t.Errorf("got %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		dst  map[string]interface{}
+		src  map[string]interface{}
+		deep bool
+		want map[string]interface{}
+	}{
+		{
+			"later wins",
+			map[string]interface{}{"a": "1", "b": "2"},
+			map[string]interface{}{"b": "3"},
+			false,
+			map[string]interface{}{"a": "1", "b": "3"},
+		},
+		{
+			"shallow replaces objects",
+			map[string]interface{}{"o": map[string]interface{}{"x": "1", "y": "2"}},
+			map[string]interface{}{"o": map[string]interface{}{"y": "3"}},
+			false,
+			map[string]interface{}{"o": map[string]interface{}{"y": "3"}},
+		},
+		{
+			"deep merges objects",
+			map[string]interface{}{"o": map[string]interface{}{"x": "1", "y": "2"}},
+			map[string]interface{}{"o": map[string]interface{}{"y": "3"}},
+			true,
+			map[string]interface{}{"o": map[string]interface{}{"x": "1", "y": "3"}},
+		},
+		{
+			"deep replaces an object with a scalar",
+			map[string]interface{}{"o": map[string]interface{}{"x": "1"}},
+			map[string]interface{}{"o": "flat"},
+			true,
+			map[string]interface{}{"o": "flat"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mergeJSON(tt.dst, tt.src, tt.deep)
+			if !reflect.DeepEqual(tt.dst, tt.want) {
+				t.Errorf("got %v, want %v", tt.dst, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package awssecret
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+)
+
+// Secret is the value of a secret along with the metadata AWS Secrets
+// Manager returns about the version that was retrieved.
+//
+type Secret struct {
+	Value         string
+	Binary        []byte
+	ARN           string
+	Name          string
+	VersionID     string
+	VersionStages []string
+	CreatedDate   time.Time
+
+	// Checksum is the hex-encoded SHA-256 of the raw value, as returned
+	// by GetSecretChecksum.
+	Checksum string
+
+	// KMSKeyID is the ID of the KMS key the secret is encrypted with. It
+	// is only populated when MetadataOptions.IncludeKMSKey is set, since
+	// it requires an extra DescribeSecret call.
+	KMSKeyID string
+
+	// VersionIDsToStages maps the VersionId of each of the secret's
+	// versions to its staging labels. It is only populated when
+	// MetadataOptions.IncludeVersionStages is set, since it requires an
+	// extra DescribeSecret call.
+	VersionIDsToStages map[string][]string
+}
+
+// MetadataOptions requests additional metadata from
+// GetSecretWithMetadataWithOptions.
+//
+type MetadataOptions struct {
+	// IncludeKMSKey populates Secret.KMSKeyID using DescribeSecret.
+	IncludeKMSKey bool
+
+	// IncludeVersionStages populates Secret.VersionIDsToStages using
+	// DescribeSecret.
+	IncludeVersionStages bool
+}
+
+// GetSecretWithMetadata retrieves the named secret from AWS Secrets Manager
+// and returns its value together with its ARN, name, version and staging
+// labels.
+//
+func GetSecretWithMetadata(sess *session.Session, secretName string) (secret *Secret, err error) {
+	return GetSecretWithMetadataWithContext(context.Background(), sess, secretName)
+}
+
+// GetSecretWithMetadataWithContext is the same as GetSecretWithMetadata, but
+// uses the supplied context for the underlying AWS request.
+//
+func GetSecretWithMetadataWithContext(ctx context.Context, sess *session.Session, secretName string) (secret *Secret, err error) {
+	c := clientFor(sess)
+	if c.err != nil {
+		return nil, c.err
+	}
+	return getSecretWithMetadata(ctx, c.api, newGetSecretValueInput(secretName, "", ""))
+}
+
+// GetSecretWithMetadataWithOptions is the same as GetSecretWithMetadata, but
+// also retrieves the additional metadata requested by opts.
+//
+func GetSecretWithMetadataWithOptions(sess *session.Session, secretName string, opts MetadataOptions) (secret *Secret, err error) {
+	return GetSecretWithMetadataWithOptionsContext(context.Background(), sess, secretName, opts)
+}
+
+// GetSecretWithMetadataWithOptionsContext is the same as
+// GetSecretWithMetadataWithOptions, but uses the supplied context for the
+// underlying AWS requests.
+//
+func GetSecretWithMetadataWithOptionsContext(ctx context.Context, sess *session.Session, secretName string, opts MetadataOptions) (secret *Secret, err error) {
+	c := clientFor(sess)
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	secret, err = getSecretWithMetadata(ctx, c.api, newGetSecretValueInput(secretName, "", ""))
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.IncludeKMSKey || opts.IncludeVersionStages {
+		svc, err := c.describer()
+		if err != nil {
+			return nil, err
+		}
+		result, err := describeSecret(ctx, svc, secretName)
+		if err != nil {
+			return nil, err
+		}
+		if opts.IncludeKMSKey {
+			secret.KMSKeyID = aws.StringValue(result.KmsKeyId)
+		}
+		if opts.IncludeVersionStages {
+			secret.VersionIDsToStages = versionIDsToStages(result.VersionIdsToStages)
+		}
+	}
+
+	return secret, nil
+}
+
+func getSecretWithMetadata(ctx context.Context, client SecretsManagerAPI, input *secretsmanager.GetSecretValueInput) (secret *Secret, err error) {
+	result, err := getSecretValue(ctx, client, input)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := result.SecretBinary
+	if result.SecretString != nil {
+		raw = []byte(*result.SecretString)
+	}
+
+	return &Secret{
+		Value:         aws.StringValue(result.SecretString),
+		Binary:        result.SecretBinary,
+		ARN:           aws.StringValue(result.ARN),
+		Name:          aws.StringValue(result.Name),
+		VersionID:     aws.StringValue(result.VersionId),
+		VersionStages: aws.StringValueSlice(result.VersionStages),
+		CreatedDate:   aws.TimeValue(result.CreatedDate),
+		Checksum:      secretChecksum(raw),
+	}, nil
+}
+
+// versionIDsToStages converts DescribeSecret's VersionIdsToStages to plain
+// strings.
+//
+func versionIDsToStages(m map[string][]*string) map[string][]string {
+	stages := make(map[string][]string, len(m))
+	for id, s := range m {
+		stages[id] = aws.StringValueSlice(s)
+	}
+	return stages
+}
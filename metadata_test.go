@@ -0,0 +1,143 @@
+package awssecret
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+)
+
+func TestGetSecretWithMetadata(t *testing.T) {
+	created := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	outputs := map[string]*secretsmanager.GetSecretValueOutput{
+		"text": {
+			ARN:           aws.String("arn:aws:secretsmanager:us-east-1:123456789012:secret:text-AbCdEf"),
+			Name:          aws.String("text"),
+			VersionId:     aws.String("v2"),
+			VersionStages: aws.StringSlice([]string{"AWSCURRENT"}),
+			CreatedDate:   aws.Time(created),
+			SecretString:  aws.String("hunter2"),
+		},
+		"bin": {
+			Name:         aws.String("bin"),
+			VersionId:    aws.String("v1"),
+			SecretBinary: []byte{1, 2, 3},
+		},
+	}
+	stub := &stubClient{get: func(input *secretsmanager.GetSecretValueInput) (*secretsmanager.GetSecretValueOutput, error) {
+		return outputs[aws.StringValue(input.SecretId)], nil
+	}}
+
+	tests := []struct {
+		secret string
+		want   Secret
+	}{
+		{"text", Secret{
+			Value:         "hunter2",
+			ARN:           "arn:aws:secretsmanager:us-east-1:123456789012:secret:text-AbCdEf",
+			Name:          "text",
+			VersionID:     "v2",
+			VersionStages: []string{"AWSCURRENT"},
+			CreatedDate:   created,
+			Checksum:      secretChecksum([]byte("hunter2")),
+		}},
+		{"bin", Secret{
+			Binary:        []byte{1, 2, 3},
+			Name:          "bin",
+			VersionID:     "v1",
+			VersionStages: []string{},
+			Checksum:      secretChecksum([]byte{1, 2, 3}),
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.secret, func(t *testing.T) {
+			got, err := getSecretWithMetadata(context.Background(), stub, newGetSecretValueInput(tt.secret, "", ""))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("got %+v\nwant %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetSecretWithMetadataSession(t *testing.T) {
+	sess := newStringSecretsSession(t, map[string]string{"db": "hunter2"})
+
+	got, err := GetSecretWithMetadata(sess, "db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Value != "hunter2" || got.Name != "db" {
+		t.Errorf("got %+v", *got)
+	}
+	if _, err := GetSecretWithMetadata(sess, "missing"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("err = %v, want ErrSecretNotFound", err)
+	}
+}
+
+func TestGetSecretWithMetadataWithOptions(t *testing.T) {
+	url, requests := newSecretsManagerServer(t, func(req smRequest) (int, interface{}) {
+		if req.Operation == "DescribeSecret" {
+			return http.StatusOK, map[string]interface{}{
+				"Name":     "db",
+				"KmsKeyId": testKMSKeyARN,
+				"VersionIdsToStages": map[string][]string{
+					"v2": {"AWSCURRENT"},
+					"v1": {"AWSPREVIOUS"},
+				},
+			}
+		}
+		return http.StatusOK, map[string]interface{}{"Name": "db", "VersionId": "v2", "SecretString": "hunter2"}
+	})
+	sess := testSession(t).Copy(aws.NewConfig().WithEndpoint(url))
+
+	tests := []struct {
+		name         string
+		opts         MetadataOptions
+		wantKMSKey   string
+		wantStages   map[string][]string
+		wantRequests []string
+	}{
+		{"none", MetadataOptions{}, "", nil, []string{"GetSecretValue"}},
+		{"kms key", MetadataOptions{IncludeKMSKey: true}, testKMSKeyARN, nil, []string{"GetSecretValue", "DescribeSecret"}},
+		{"version stages", MetadataOptions{IncludeVersionStages: true}, "", map[string][]string{"v2": {"AWSCURRENT"}, "v1": {"AWSPREVIOUS"}}, []string{"GetSecretValue", "DescribeSecret"}},
+		{"both", MetadataOptions{IncludeKMSKey: true, IncludeVersionStages: true}, testKMSKeyARN, map[string][]string{"v2": {"AWSCURRENT"}, "v1": {"AWSPREVIOUS"}}, []string{"GetSecretValue", "DescribeSecret"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret, err := GetSecretWithMetadataWithOptions(sess, "db", tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if secret.Value != "hunter2" {
+				t.Errorf("Value = %q, want hunter2", secret.Value)
+			}
+			if secret.KMSKeyID != tt.wantKMSKey {
+				t.Errorf("KMSKeyID = %q, want %q", secret.KMSKeyID, tt.wantKMSKey)
+			}
+			if !reflect.DeepEqual(secret.VersionIDsToStages, tt.wantStages) {
+				t.Errorf("VersionIDsToStages = %v, want %v", secret.VersionIDsToStages, tt.wantStages)
+			}
+
+			var ops []string
+			for len(ops) < len(tt.wantRequests) {
+				ops = append(ops, (<-requests).Operation)
+			}
+			if !reflect.DeepEqual(ops, tt.wantRequests) {
+				t.Errorf("operations = %v, want %v", ops, tt.wantRequests)
+			}
+			select {
+			case req := <-requests:
+				t.Errorf("unexpected %s request", req.Operation)
+			default:
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package awssecret
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+// DefaultConcurrency is the number of secrets GetStringSecrets retrieves at
+// once.
+const DefaultConcurrency = 5
+
+// SecretErrors reports the secrets which couldn't be retrieved by one of the
+// functions which read several secrets at once, keyed by secret name.
+//
+type SecretErrors map[string]error
+
+func (e SecretErrors) Error() string {
+	names := make([]string, 0, len(e))
+	for name := range e {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	msgs := make([]string, 0, len(names))
+	for _, name := range names {
+		msgs = append(msgs, fmt.Sprintf("%s: %s", name, e[name]))
+	}
+	return fmt.Sprintf("Failed to get %d secret(s) from AWS Secrets Manager: %s", len(e), strings.Join(msgs, "; "))
+}
+
+// GetStringSecrets retrieves each of the named secrets from AWS Secrets
+// Manager concurrently and returns their raw values keyed by name. Failures
+// don't stop the remaining secrets from being retrieved: the values which
+// could be read are returned along with a SecretErrors describing the rest.
+//
+func GetStringSecrets(sess *session.Session, secretNames []string) (secrets map[string]string, err error) {
+	return GetStringSecretsWithContext(context.Background(), sess, secretNames)
+}
+
+// GetStringSecretsWithContext is the same as GetStringSecrets, but uses the
+// supplied context for the underlying AWS requests. Secrets which haven't
+// been requested by the time the context is cancelled fail with the
+// context's error.
+//
+func GetStringSecretsWithContext(ctx context.Context, sess *session.Session, secretNames []string) (secrets map[string]string, err error) {
+	c := clientFor(sess)
+	if c.err != nil {
+		return nil, c.err
+	}
+	return GetStringSecretsWithClientContext(ctx, c.api, secretNames, DefaultConcurrency)
+}
+
+// GetStringSecretsWithClientContext is the same as
+// GetStringSecretsWithContext, but uses the supplied client and retrieves at
+// most concurrency secrets at once. A concurrency of zero or less uses
+// DefaultConcurrency.
+//
+func GetStringSecretsWithClientContext(ctx context.Context, client SecretsManagerAPI, secretNames []string, concurrency int) (secrets map[string]string, err error) {
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+
+	names := make(chan string)
+	go func() {
+		defer close(names)
+		for _, name := range secretNames {
+			names <- name
+		}
+	}()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	secrets = make(map[string]string, len(secretNames))
+	errs := SecretErrors{}
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for name := range names {
+				value, err := GetStringSecretWithClientContext(ctx, client, name)
+
+				mu.Lock()
+				if err != nil {
+					errs[name] = err
+				} else {
+					secrets[name] = value
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return secrets, errs
+	}
+	return secrets, nil
+}
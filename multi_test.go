@@ -0,0 +1,109 @@
+package awssecret
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+)
+
+func TestGetStringSecrets(t *testing.T) {
+	sess := newStringSecretsSession(t, map[string]string{"a": "1", "b": "2", "c": "3"})
+
+	tests := []struct {
+		name       string
+		secrets    []string
+		want       map[string]string
+		wantFailed []string
+	}{
+		{"all found", []string{"a", "b", "c"}, map[string]string{"a": "1", "b": "2", "c": "3"}, nil},
+		{"partial failure", []string{"a", "missing", "c"}, map[string]string{"a": "1", "c": "3"}, []string{"missing"}},
+		{"none", nil, map[string]string{}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetStringSecrets(sess, tt.secrets)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			if tt.wantFailed == nil {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			var errs SecretErrors
+			if !errors.As(err, &errs) {
+				t.Fatalf("err = %v, want SecretErrors", err)
+			}
+			for _, name := range tt.wantFailed {
+				if !errors.Is(errs[name], ErrSecretNotFound) {
+					t.Errorf("errs[%q] = %v, want ErrSecretNotFound", name, errs[name])
+				}
+			}
+		})
+	}
+}
+
+func TestGetStringSecretsWithClientContextConcurrency(t *testing.T) {
+	tests := []struct {
+		concurrency int
+		wantMax     int
+	}{
+		{1, 1},
+		{3, 3},
+		{0, DefaultConcurrency},
+	}
+	for _, tt := range tests {
+		t.Run("", func(t *testing.T) {
+			var mu sync.Mutex
+			var inFlight, maxInFlight int
+			stub := &stubClient{get: func(input *secretsmanager.GetSecretValueInput) (*secretsmanager.GetSecretValueOutput, error) {
+				mu.Lock()
+				inFlight++
+				if inFlight > maxInFlight {
+					maxInFlight = inFlight
+				}
+				mu.Unlock()
+
+				time.Sleep(time.Millisecond)
+
+				mu.Lock()
+				inFlight--
+				mu.Unlock()
+				return &secretsmanager.GetSecretValueOutput{SecretString: input.SecretId}, nil
+			}}
+
+			names := make([]string, 20)
+			for i := range names {
+				names[i] = string(rune('a' + i))
+			}
+			got, err := GetStringSecretsWithClientContext(context.Background(), stub, names, tt.concurrency)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(names) {
+				t.Errorf("got %d secrets, want %d", len(got), len(names))
+			}
+			if maxInFlight > tt.wantMax {
+				t.Errorf("max in flight = %d, want at most %d", maxInFlight, tt.wantMax)
+			}
+		})
+	}
+}
+
+func TestSecretErrorsError(t *testing.T) {
+	errs := SecretErrors{
+		"b": awserr.New("Throttling", "slow down", nil),
+		"a": errors.New("boom"),
+	}
+	want := "Failed to get 2 secret(s) from AWS Secrets Manager: a: boom; b: Throttling: slow down"
+	if got := errs.Error(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,144 @@
+package awssecret
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/pkg/errors"
+)
+
+// GetMySQLDSNSecret retrieves the named secret from AWS Secrets Manager
+// and converts it from the JSON its natively stored as into a DSN string
+// compatible with github.com/go-sql-driver/mysql, of the form
+// user:password@tcp(host:port)/dbname
+//
+// The driver splits the user and password on the last '@' and the first ':',
+// so passwords containing '@', ':' or '/' are written as-is. Escaping them
+// would change the password the driver sends to the server.
+//
+func GetMySQLDSNSecret(sess *session.Session, secretName string) (dsnStr string, err error) {
+	return GetMySQLDSNSecretWithContext(context.Background(), sess, secretName)
+}
+
+// GetMySQLDSNSecretWithContext is the same as GetMySQLDSNSecret, but
+// uses the supplied context for the underlying AWS request.
+//
+func GetMySQLDSNSecretWithContext(ctx context.Context, sess *session.Session, secretName string) (dsnStr string, err error) {
+	return GetMySQLDSNSecretWithOptionsContext(ctx, sess, secretName, MySQLDSNOptions{})
+}
+
+// MySQLDSNOptions adds connection parameters to the DSN built by
+// GetMySQLDSNSecretWithOptions.
+//
+type MySQLDSNOptions struct {
+	// Params are added to the DSN's query string, e.g. "parseTime": "true".
+	// They take precedence over parameters read from the secret.
+	Params map[string]string
+}
+
+type mysqlSecret struct {
+	dsn
+	TLS json.RawMessage `json:"tls"`
+}
+
+// GetMySQLDSNSecretWithOptions is the same as GetMySQLDSNSecret, but adds
+// the supplied connection parameters to the DSN. A "tls" field in the secret
+// (either a string such as "skip-verify" or a boolean) is also added as the
+// tls parameter. Parameters are sorted by name so that the DSN is
+// reproducible.
+//
+func GetMySQLDSNSecretWithOptions(sess *session.Session, secretName string, opts MySQLDSNOptions) (dsnStr string, err error) {
+	return GetMySQLDSNSecretWithOptionsContext(context.Background(), sess, secretName, opts)
+}
+
+// GetMySQLDSNSecretWithOptionsContext is the same as
+// GetMySQLDSNSecretWithOptions, but uses the supplied context for the
+// underlying AWS request.
+//
+func GetMySQLDSNSecretWithOptionsContext(ctx context.Context, sess *session.Session, secretName string, opts MySQLDSNOptions) (dsnStr string, err error) {
+	str, err := GetStringSecretWithContext(ctx, sess, secretName)
+	if err != nil {
+		return "", errors.Wrapf(err, "Couldn't build DSN. Failed to retrieve secret")
+	}
+	return mysqlDSN(str, opts)
+}
+
+// mysqlDSN converts a secret from JSON into a go-sql-driver/mysql DSN.
+//
+func mysqlDSN(str string, opts MySQLDSNOptions) (dsnStr string, err error) {
+	m := mysqlSecret{}
+	err = Unmarshal([]byte(str), &m)
+	if err != nil {
+		return "", errors.Wrapf(err, "Couldn't build DSN. Failed to decode JSON")
+	}
+	d := m.dsn
+
+	params := map[string]string{}
+	if len(m.TLS) > 0 && string(m.TLS) != "null" {
+		var tls interface{}
+		err = Unmarshal(m.TLS, &tls)
+		if err != nil {
+			return "", errors.Wrapf(err, "Couldn't build DSN. Failed to decode tls field")
+		}
+		switch tls := tls.(type) {
+		case string:
+			params["tls"] = tls
+		case bool:
+			params["tls"] = strconv.FormatBool(tls)
+		default:
+			return "", errors.New("Couldn't build DSN. The tls field must be a string or boolean")
+		}
+	}
+	for k, v := range opts.Params {
+		params[k] = v
+	}
+
+	s := strings.Builder{}
+	if d.Username != "" {
+		s.WriteString(d.Username)
+		if d.Password != "" {
+			s.WriteString(":")
+			s.WriteString(d.Password)
+		}
+		s.WriteString("@")
+	}
+
+	if d.Host != "" {
+		s.WriteString("tcp(")
+		s.WriteString(d.Host)
+		if d.Port != 0 {
+			s.WriteString(":")
+			s.WriteString(strconv.Itoa(d.Port))
+		}
+		s.WriteString(")")
+	}
+
+	s.WriteString("/")
+	s.WriteString(d.DBName)
+
+	if len(params) > 0 {
+		keys := make([]string, 0, len(params))
+		for k := range params {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for i, k := range keys {
+			if i == 0 {
+				s.WriteString("?")
+			} else {
+				s.WriteString("&")
+			}
+			s.WriteString(k)
+			s.WriteString("=")
+			s.WriteString(url.QueryEscape(params[k]))
+		}
+	}
+
+	return s.String(), nil
+}
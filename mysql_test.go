@@ -0,0 +1,78 @@
+package awssecret
+
+import "testing"
+
+func TestGetMySQLDSNSecret(t *testing.T) {
+	tests := []struct {
+		name    string
+		secret  string
+		want    string
+		wantErr bool
+	}{
+		{"full", `{"host":"db.local","port":3306,"username":"admin","password":"pw","dbname":"app"}`, "admin:pw@tcp(db.local:3306)/app", false},
+		{"no port", `{"host":"db.local","username":"admin","password":"pw","dbname":"app"}`, "admin:pw@tcp(db.local)/app", false},
+		{"no password", `{"host":"db.local","username":"admin","dbname":"app"}`, "admin@tcp(db.local)/app", false},
+		{"password with separators", `{"host":"db.local","username":"admin","password":"p@s:s/w","dbname":"app"}`, "admin:p@s:s/w@tcp(db.local)/app", false},
+		{"no dbname", `{"host":"db.local","username":"admin"}`, "admin@tcp(db.local)/", false},
+		{"not json", `hunter2`, "", true},
+	}
+	values := make(map[string]string)
+	for _, tt := range tests {
+		values[tt.name] = tt.secret
+	}
+	sess := newStringSecretsSession(t, values)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetMySQLDSNSecret(sess, tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := GetMySQLDSNSecret(sess, "missing"); err == nil {
+		t.Error("expected an error for a missing secret")
+	}
+}
+
+func TestMySQLDSNParams(t *testing.T) {
+	const base = `"host":"db.local","username":"admin","password":"pw","dbname":"app"`
+
+	tests := []struct {
+		name    string
+		secret  string
+		params  map[string]string
+		want    string
+		wantErr bool
+	}{
+		{"parseTime", `{` + base + `}`, map[string]string{"parseTime": "true"}, "admin:pw@tcp(db.local)/app?parseTime=true", false},
+		{"params are sorted", `{` + base + `}`, map[string]string{"parseTime": "true", "loc": "Local", "charset": "utf8mb4"}, "admin:pw@tcp(db.local)/app?charset=utf8mb4&loc=Local&parseTime=true", false},
+		{"values are escaped", `{` + base + `}`, map[string]string{"loc": "America/New_York"}, "admin:pw@tcp(db.local)/app?loc=America%2FNew_York", false},
+		{"tls string from secret", `{` + base + `,"tls":"skip-verify"}`, nil, "admin:pw@tcp(db.local)/app?tls=skip-verify", false},
+		{"tls bool from secret", `{` + base + `,"tls":true}`, nil, "admin:pw@tcp(db.local)/app?tls=true", false},
+		{"tls null is ignored", `{` + base + `,"tls":null}`, nil, "admin:pw@tcp(db.local)/app", false},
+		{"options override the secret", `{` + base + `,"tls":true}`, map[string]string{"tls": "custom"}, "admin:pw@tcp(db.local)/app?tls=custom", false},
+		{"tls of another type", `{` + base + `,"tls":1}`, nil, "", true},
+	}
+	values := make(map[string]string)
+	for _, tt := range tests {
+		values[tt.name] = tt.secret
+	}
+	sess := newStringSecretsSession(t, values)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetMySQLDSNSecretWithOptions(sess, tt.name, MySQLDSNOptions{Params: tt.params})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
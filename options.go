@@ -0,0 +1,114 @@
+package awssecret
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/pkg/errors"
+)
+
+// GetOptions adjusts how GetStringSecretWithOptions retrieves a secret.
+//
+type GetOptions struct {
+	// Timeout bounds how long retrieving the secret may take, for callers
+	// who don't otherwise use a context. Zero means no timeout.
+	Timeout time.Duration
+
+	// Transform, when set, is applied to the secret's raw value before it
+	// is returned, e.g. to decrypt a value protected by an application
+	// level key. The raw value is the SecretString if present, otherwise
+	// the SecretBinary. The default is to return the value unchanged.
+	Transform func([]byte) ([]byte, error)
+}
+
+// GetStringSecretWithOptions is the same as GetStringSecret, but retrieves
+// the secret according to opts.
+//
+func GetStringSecretWithOptions(sess *session.Session, secretName string, opts GetOptions) (secret string, err error) {
+	return GetStringSecretWithOptionsContext(context.Background(), sess, secretName, opts)
+}
+
+// GetStringSecretWithOptionsContext is the same as
+// GetStringSecretWithOptions, but uses the supplied context for the
+// underlying AWS request. A Timeout in opts further limits the context.
+//
+func GetStringSecretWithOptionsContext(ctx context.Context, sess *session.Session, secretName string, opts GetOptions) (secret string, err error) {
+	return getStringSecretWithOptions(ctx, clientFor(sess), secretName, opts)
+}
+
+// GetStringSecretWithOptionsWithClientContext is the same as
+// GetStringSecretWithOptionsContext, but uses the supplied client.
+//
+func GetStringSecretWithOptionsWithClientContext(ctx context.Context, client SecretsManagerAPI, secretName string, opts GetOptions) (secret string, err error) {
+	return getStringSecretWithOptions(ctx, NewClientWithAPI(client), secretName, opts)
+}
+
+// getStringSecretWithOptions retrieves the secret through c. If the request
+// fails because the context ended, the context's error is returned (wrapped)
+// so that it matches context.DeadlineExceeded or context.Canceled via
+// errors.Is; the SDK's RequestCanceled error doesn't unwrap to it.
+//
+func getStringSecretWithOptions(ctx context.Context, c *Client, secretName string, opts GetOptions) (secret string, err error) {
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	var data []byte
+	if opts.Transform == nil {
+		secret, err = c.GetStringWithContext(ctx, secretName)
+	} else {
+		data, err = transformSecret(ctx, c, secretName, opts.Transform)
+		secret = string(data)
+	}
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return "", errors.Wrapf(ctxErr, "Failed to get secret %q: %s", secretName, err)
+		}
+		return "", err
+	}
+	return secret, nil
+}
+
+// GetStringSecretWith retrieves the named secret from AWS Secrets Manager
+// and returns the result of passing its raw string value to decode. It is
+// intended for secrets which are decrypted or unwrapped client-side, such
+// as JWE compact tokens, with the cryptography supplied by the caller.
+//
+func GetStringSecretWith(sess *session.Session, secretName string, decode func(string) (string, error)) (secret string, err error) {
+	return GetStringSecretWithDecoderContext(context.Background(), sess, secretName, decode)
+}
+
+// GetStringSecretWithDecoderContext is the same as GetStringSecretWith, but
+// uses the supplied context for the underlying AWS request.
+//
+func GetStringSecretWithDecoderContext(ctx context.Context, sess *session.Session, secretName string, decode func(string) (string, error)) (secret string, err error) {
+	secret, err = GetStringSecretWithContext(ctx, sess, secretName)
+	if err != nil {
+		return "", err
+	}
+
+	secret, err = decode(secret)
+	if err != nil {
+		return "", errors.Wrapf(err, "Failed to decode secret %q", secretName)
+	}
+	return secret, nil
+}
+
+// transformSecret retrieves the named secret's raw value and applies
+// transform to it.
+//
+func transformSecret(ctx context.Context, c *Client, secretName string, transform func([]byte) ([]byte, error)) ([]byte, error) {
+	data, err := getSecretBytes(ctx, c, secretName)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err = transform(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to transform secret %q", secretName)
+	}
+	return data, nil
+}
@@ -0,0 +1,129 @@
+package awssecret
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+)
+
+// blockingClient never answers until the request's context ends, and then
+// fails the way the real SDK client does.
+type blockingClient struct{}
+
+func (blockingClient) GetSecretValue(*secretsmanager.GetSecretValueInput) (*secretsmanager.GetSecretValueOutput, error) {
+	panic("GetSecretValueWithContext should be used")
+}
+
+func (blockingClient) GetSecretValueWithContext(ctx context.Context, _ *secretsmanager.GetSecretValueInput, _ ...request.Option) (*secretsmanager.GetSecretValueOutput, error) {
+	<-ctx.Done()
+	return nil, awserr.New(request.CanceledErrorCode, "request context canceled", ctx.Err())
+}
+
+func TestGetStringSecretWithOptionsTimeout(t *testing.T) {
+	start := time.Now()
+	_, err := GetStringSecretWithOptionsWithClientContext(context.Background(), blockingClient{}, "db", GetOptions{Timeout: 20 * time.Millisecond})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("took %s, want roughly the timeout", elapsed)
+	}
+}
+
+func TestGetStringSecretWithOptionsCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := GetStringSecretWithOptionsWithClientContext(ctx, blockingClient{}, "db", GetOptions{})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+}
+
+func TestGetStringSecretWithOptions(t *testing.T) {
+	reverse := func(b []byte) ([]byte, error) {
+		out := make([]byte, len(b))
+		for i := range b {
+			out[len(b)-1-i] = b[i]
+		}
+		return out, nil
+	}
+	failing := func([]byte) ([]byte, error) { return nil, errors.New("bad key") }
+
+	tests := []struct {
+		name    string
+		opts    GetOptions
+		want    string
+		wantErr bool
+	}{
+		{name: "defaults", want: "olleh"},
+		{name: "generous timeout", opts: GetOptions{Timeout: time.Minute}, want: "olleh"},
+		{name: "transform", opts: GetOptions{Transform: reverse}, want: "hello"},
+		{name: "failing transform", opts: GetOptions{Transform: failing}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newStringStub(map[string]string{"db": "olleh"})
+			got, err := GetStringSecretWithOptionsWithClientContext(context.Background(), client, "db", tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetStringSecretWithOptionsTransformsBinary(t *testing.T) {
+	client := &stubClient{get: func(*secretsmanager.GetSecretValueInput) (*secretsmanager.GetSecretValueOutput, error) {
+		return &secretsmanager.GetSecretValueOutput{Name: aws.String("db"), SecretBinary: []byte{1, 2}}, nil
+	}}
+	got, err := GetStringSecretWithOptionsWithClientContext(context.Background(), client, "db", GetOptions{
+		Transform: func(b []byte) ([]byte, error) { return []byte{'0' + b[0], '0' + b[1]}, nil },
+	})
+	if err != nil || got != "12" {
+		t.Fatalf("got %q, %v; want \"12\"", got, err)
+	}
+}
+
+func TestGetStringSecretWith(t *testing.T) {
+	useDefaultAPI(t, newStringStub(map[string]string{
+		"token": "eyJhbGciOiJkaXIifQ..iv.ciphertext.tag",
+	}))
+
+	errBadKey := errors.New("bad key")
+	identity := func(s string) (string, error) { return s, nil }
+	payload := func(s string) (string, error) { return strings.Split(s, ".")[3], nil }
+	failing := func(string) (string, error) { return "", errBadKey }
+
+	tests := []struct {
+		name    string
+		secret  string
+		decode  func(string) (string, error)
+		want    string
+		wantErr error
+	}{
+		{"identity", "token", identity, "eyJhbGciOiJkaXIifQ..iv.ciphertext.tag", nil},
+		{"decoder result is returned", "token", payload, "ciphertext", nil},
+		{"failing decoder", "token", failing, "", errBadKey},
+		{"missing secret", "missing", failing, "", ErrSecretNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetStringSecretWith(nil, tt.secret, tt.decode)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package awssecret
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/pkg/errors"
+)
+
+type oracleSecret struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	DBName   string `json:"dbname"`
+	Service  string `json:"service"`
+	SID      string `json:"sid"`
+}
+
+// GetOracleDSNSecret retrieves the named secret from AWS Secrets Manager and
+// converts it from JSON into a github.com/godror/godror connection string of
+// the form user/password@host:port/service. The service name comes from an
+// optional "service" field, falling back to "dbname". If a "sid" field is
+// present instead, a full connect descriptor naming the SID is used, since
+// the short form can't express one. The port defaults to 1521.
+//
+func GetOracleDSNSecret(sess *session.Session, secretName string) (dsnStr string, err error) {
+	return GetOracleDSNSecretWithContext(context.Background(), sess, secretName)
+}
+
+// GetOracleDSNSecretWithContext is the same as GetOracleDSNSecret, but uses
+// the supplied context for the underlying AWS request.
+//
+func GetOracleDSNSecretWithContext(ctx context.Context, sess *session.Session, secretName string) (dsnStr string, err error) {
+	str, err := GetStringSecretWithContext(ctx, sess, secretName)
+	if err != nil {
+		return "", errors.Wrapf(err, "Couldn't build DSN. Failed to retrieve secret")
+	}
+	return oracleDSN(str)
+}
+
+// oracleDSN converts a secret from JSON into a godror connection string.
+//
+func oracleDSN(str string) (dsnStr string, err error) {
+	d := oracleSecret{}
+	err = Unmarshal([]byte(str), &d)
+	if err != nil {
+		return "", errors.Wrapf(err, "Couldn't build DSN. Failed to decode JSON")
+	}
+
+	if d.Port == 0 {
+		d.Port = 1521
+	}
+
+	username, err := quoteOracleValue(d.Username)
+	if err != nil {
+		return "", errors.Wrapf(err, "Couldn't build DSN. Invalid username")
+	}
+	password, err := quoteOracleValue(d.Password)
+	if err != nil {
+		return "", errors.Wrapf(err, "Couldn't build DSN. Invalid password")
+	}
+
+	var connect string
+	service := d.Service
+	if service == "" && d.SID == "" {
+		service = d.DBName
+	}
+	if service != "" {
+		connect = d.Host + ":" + strconv.Itoa(d.Port) + "/" + service
+	} else {
+		connect = fmt.Sprintf("(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=%s)(PORT=%d))(CONNECT_DATA=(SID=%s)))", d.Host, d.Port, d.SID)
+	}
+
+	return username + "/" + password + "@" + connect, nil
+}
+
+// quoteOracleValue double-quotes a username or password unless it consists
+// only of characters Oracle accepts unquoted. Oracle has no way to escape a
+// double quote within a quoted value, so such values are rejected.
+//
+func quoteOracleValue(s string) (string, error) {
+	if strings.ContainsRune(s, '"') {
+		return "", errors.New("Oracle credentials can't contain a double quote")
+	}
+	if s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '$' || r == '#')
+	}) < 0 {
+		return s, nil
+	}
+	return `"` + s + `"`, nil
+}
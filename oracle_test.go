@@ -0,0 +1,52 @@
+package awssecret
+
+import "testing"
+
+func TestOracleDSN(t *testing.T) {
+	tests := []struct {
+		name    string
+		secret  string
+		want    string
+		wantErr bool
+	}{
+		{"dbname as service", `{"host":"db.local","port":1522,"username":"admin","password":"pw","dbname":"ORCL"}`, "admin/pw@db.local:1522/ORCL", false},
+		{"default port", `{"host":"db.local","username":"admin","password":"pw","dbname":"ORCL"}`, "admin/pw@db.local:1521/ORCL", false},
+		{"service wins over dbname", `{"host":"db.local","username":"admin","password":"pw","dbname":"ORCL","service":"orcl.example.com"}`, "admin/pw@db.local:1521/orcl.example.com", false},
+		{"sid", `{"host":"db.local","username":"admin","password":"pw","dbname":"ORCL","sid":"XE"}`, "admin/pw@(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=db.local)(PORT=1521))(CONNECT_DATA=(SID=XE)))", false},
+		{"allowed unquoted characters", `{"host":"db.local","username":"app_user$1","password":"Pw#123","dbname":"ORCL"}`, "app_user$1/Pw#123@db.local:1521/ORCL", false},
+		{"special characters are quoted", `{"host":"db.local","username":"admin","password":"p@ss/w:rd","dbname":"ORCL"}`, `admin/"p@ss/w:rd"@db.local:1521/ORCL`, false},
+		{"empty password is quoted", `{"host":"db.local","username":"admin","dbname":"ORCL"}`, `admin/""@db.local:1521/ORCL`, false},
+		{"double quote in password", `{"host":"db.local","username":"admin","password":"p\"w","dbname":"ORCL"}`, "", true},
+		{"double quote in username", `{"host":"db.local","username":"ad\"min","password":"pw","dbname":"ORCL"}`, "", true},
+		{"not json", `hunter2`, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := oracleDSN(tt.secret)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetOracleDSNSecret(t *testing.T) {
+	sess := newStringSecretsSession(t, map[string]string{
+		"db": `{"host":"db.local","port":1521,"username":"admin","password":"pw","dbname":"ORCL"}`,
+	})
+
+	got, err := GetOracleDSNSecret(sess, "db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "admin/pw@db.local:1521/ORCL"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	if _, err := GetOracleDSNSecret(sess, "missing"); err == nil {
+		t.Error("expected an error for a missing secret")
+	}
+}
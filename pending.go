@@ -0,0 +1,92 @@
+package awssecret
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/pkg/errors"
+)
+
+// PendingVersionStage is the staging label rotation attaches to a new
+// version of a secret while it is being set and tested.
+const PendingVersionStage = "AWSPENDING"
+
+// PromotePendingAPI is the subset of the AWS Secrets Manager client used to
+// promote a pending version of a secret.
+//
+type PromotePendingAPI interface {
+	DescribeSecretAPI
+	UpdateSecretVersionStageWithContext(context.Context, *secretsmanager.UpdateSecretVersionStageInput, ...request.Option) (*secretsmanager.UpdateSecretVersionStageOutput, error)
+}
+
+// GetPendingStringSecret retrieves the AWSPENDING version of the named
+// secret, as a rotation function does in its setSecret and testSecret
+// steps.
+//
+func GetPendingStringSecret(sess *session.Session, secretName string) (secret string, err error) {
+	return GetPendingStringSecretWithContext(context.Background(), sess, secretName)
+}
+
+// GetPendingStringSecretWithContext is the same as GetPendingStringSecret,
+// but uses the supplied context for the underlying AWS request.
+//
+func GetPendingStringSecretWithContext(ctx context.Context, sess *session.Session, secretName string) (secret string, err error) {
+	return GetStringSecretVersionWithContext(ctx, sess, secretName, "", PendingVersionStage)
+}
+
+// PromotePending moves the AWSCURRENT label of the named secret to its
+// AWSPENDING version, as a rotation function does in its finishSecret step.
+// The previously current version is automatically labeled AWSPREVIOUS by
+// AWS. Nothing is changed if the pending version is already current.
+//
+func PromotePending(sess *session.Session, secretName string) error {
+	return PromotePendingWithContext(context.Background(), sess, secretName)
+}
+
+// PromotePendingWithContext is the same as PromotePending, but uses the
+// supplied context for the underlying AWS requests.
+//
+func PromotePendingWithContext(ctx context.Context, sess *session.Session, secretName string) error {
+	svc, err := newService(sess, ClientOptions{})
+	if err != nil {
+		return err
+	}
+	return PromotePendingWithClientContext(ctx, svc, secretName)
+}
+
+// PromotePendingWithClientContext is the same as PromotePendingWithContext,
+// but uses the supplied client.
+//
+func PromotePendingWithClientContext(ctx context.Context, client PromotePendingAPI, secretName string) error {
+	result, err := describeSecret(ctx, client, secretName)
+	if err != nil {
+		return err
+	}
+
+	pending := versionWithStage(result.VersionIdsToStages, PendingVersionStage)
+	if pending == "" {
+		return errors.Errorf("Secret %q has no %s version to promote", secretName, PendingVersionStage)
+	}
+	current := versionWithStage(result.VersionIdsToStages, DefaultVersionStage)
+	if current == pending {
+		return nil
+	}
+
+	input := &secretsmanager.UpdateSecretVersionStageInput{
+		SecretId:        aws.String(secretName),
+		VersionStage:    aws.String(DefaultVersionStage),
+		MoveToVersionId: aws.String(pending),
+	}
+	if current != "" {
+		input.RemoveFromVersionId = aws.String(current)
+	}
+
+	_, err = client.UpdateSecretVersionStageWithContext(ctx, input)
+	if err != nil {
+		return errors.Wrapf(mapAWSError(secretName, err), "Failed to promote pending version of secret %q in AWS Secrets Manager", secretName)
+	}
+	return nil
+}
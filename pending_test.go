@@ -0,0 +1,127 @@
+package awssecret
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/adlio/awssecret/awssecrettest"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+)
+
+// promoteStub is a hand-rolled PromotePendingAPI whose secret "db" has the
+// given versions. Each UpdateSecretVersionStage input is recorded, and
+// fails with updateErr if it is set.
+type promoteStub struct {
+	*describeStub
+	updateErr error
+	updates   []*secretsmanager.UpdateSecretVersionStageInput
+}
+
+func newPromoteStub(versions map[string][]string) *promoteStub {
+	stages := map[string][]*string{}
+	for id, labels := range versions {
+		stages[id] = aws.StringSlice(labels)
+	}
+	return &promoteStub{describeStub: &describeStub{outputs: map[string]*secretsmanager.DescribeSecretOutput{
+		"db": {Name: aws.String("db"), VersionIdsToStages: stages},
+	}}}
+}
+
+func (s *promoteStub) UpdateSecretVersionStageWithContext(ctx context.Context, input *secretsmanager.UpdateSecretVersionStageInput, opts ...request.Option) (*secretsmanager.UpdateSecretVersionStageOutput, error) {
+	s.updates = append(s.updates, input)
+	if s.updateErr != nil {
+		return nil, s.updateErr
+	}
+	return &secretsmanager.UpdateSecretVersionStageOutput{Name: input.SecretId}, nil
+}
+
+func TestPromotePending(t *testing.T) {
+	tests := []struct {
+		name       string
+		versions   map[string][]string
+		wantUpdate bool
+		wantMove   string
+		wantRemove string
+		wantErr    bool
+	}{
+		{
+			name:       "pending and current",
+			versions:   map[string][]string{"v1": {"AWSCURRENT"}, "v2": {"AWSPENDING"}},
+			wantUpdate: true, wantMove: "v2", wantRemove: "v1",
+		},
+		{
+			name:       "pending only",
+			versions:   map[string][]string{"v2": {"AWSPENDING"}},
+			wantUpdate: true, wantMove: "v2",
+		},
+		{
+			name:     "already current",
+			versions: map[string][]string{"v2": {"AWSCURRENT", "AWSPENDING"}},
+		},
+		{
+			name:     "no pending version",
+			versions: map[string][]string{"v1": {"AWSCURRENT"}},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := newPromoteStub(tt.versions)
+			err := PromotePendingWithClientContext(context.Background(), stub, "db")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantUpdate {
+				if len(stub.updates) != 0 {
+					t.Errorf("UpdateSecretVersionStage called %d times, want 0", len(stub.updates))
+				}
+				return
+			}
+			if len(stub.updates) != 1 {
+				t.Fatalf("UpdateSecretVersionStage called %d times, want 1", len(stub.updates))
+			}
+			update := stub.updates[0]
+			if aws.StringValue(update.SecretId) != "db" || aws.StringValue(update.VersionStage) != "AWSCURRENT" {
+				t.Errorf("update = %v, want AWSCURRENT of db", update)
+			}
+			if got := aws.StringValue(update.MoveToVersionId); got != tt.wantMove {
+				t.Errorf("MoveToVersionId = %q, want %q", got, tt.wantMove)
+			}
+			if got := aws.StringValue(update.RemoveFromVersionId); got != tt.wantRemove {
+				t.Errorf("RemoveFromVersionId = %q, want %q", got, tt.wantRemove)
+			}
+		})
+	}
+}
+
+func TestPromotePendingErrors(t *testing.T) {
+	if err := PromotePendingWithClientContext(context.Background(), newPromoteStub(nil), "missing"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("missing secret: err = %v, want ErrSecretNotFound", err)
+	}
+
+	stub := newPromoteStub(map[string][]string{"v1": {"AWSCURRENT"}, "v2": {"AWSPENDING"}})
+	stub.updateErr = awserr.New(secretsmanager.ErrCodeInvalidRequestException, "denied", nil)
+	if err := PromotePendingWithClientContext(context.Background(), stub, "db"); !errors.Is(err, ErrInvalidRequest) {
+		t.Errorf("failed update: err = %v, want ErrInvalidRequest", err)
+	}
+}
+
+func TestGetPendingStringSecret(t *testing.T) {
+	fake := awssecrettest.NewFakeClient()
+	fake.SetStringVersion("db", "AWSCURRENT", "current")
+	fake.SetStringVersion("db", "AWSPENDING", "pending")
+	fake.SetString("unrotated", "current")
+	useDefaultAPI(t, fake)
+
+	got, err := GetPendingStringSecret(nil, "db")
+	if err != nil || got != "pending" {
+		t.Errorf("got %q, %v; want pending", got, err)
+	}
+	if _, err := GetPendingStringSecret(nil, "unrotated"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("err = %v, want ErrSecretNotFound", err)
+	}
+}
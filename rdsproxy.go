@@ -0,0 +1,34 @@
+package awssecret
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/pkg/errors"
+)
+
+// GetRDSProxyCredential retrieves the named secret as GetCredentialSecret
+// does, and checks that it has the username and password RDS Proxy
+// requires. A secret missing either can be attached to a proxy, but the
+// proxy can't use it to connect, so this catches the mistake early.
+//
+func GetRDSProxyCredential(sess *session.Session, secretName string) (cred *Credential, err error) {
+	return GetRDSProxyCredentialWithContext(context.Background(), sess, secretName)
+}
+
+// GetRDSProxyCredentialWithContext is the same as GetRDSProxyCredential, but
+// uses the supplied context for the underlying AWS request.
+//
+func GetRDSProxyCredentialWithContext(ctx context.Context, sess *session.Session, secretName string) (cred *Credential, err error) {
+	cred, err = GetCredentialSecretWithContext(ctx, sess, secretName)
+	if err != nil {
+		return cred, err
+	}
+
+	err = cred.Validate(CredentialUsername | CredentialPassword)
+	if err != nil {
+		return cred, errors.Wrapf(err, "Secret %s can't be used by RDS Proxy, which requires a username and password", secretName)
+	}
+
+	return cred, nil
+}
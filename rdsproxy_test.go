@@ -0,0 +1,50 @@
+package awssecret
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestGetRDSProxyCredential(t *testing.T) {
+	sess := newStringSecretsSession(t, map[string]string{
+		"valid":       `{"host":"db.local","username":"admin","password":"pw"}`,
+		"no-username": `{"host":"db.local","password":"pw"}`,
+		"no-password": `{"host":"db.local","username":"admin"}`,
+		"neither":     `{"host":"db.local"}`,
+	})
+
+	tests := []struct {
+		secret      string
+		wantErr     bool
+		wantMissing []string
+	}{
+		{"valid", false, nil},
+		{"no-username", true, []string{"username"}},
+		{"no-password", true, []string{"password"}},
+		{"neither", true, []string{"username", "password"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.secret, func(t *testing.T) {
+			cred, err := GetRDSProxyCredential(sess, tt.secret)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if cred == nil || cred.Host != "db.local" {
+				t.Errorf("cred = %+v, want the decoded credential", cred)
+			}
+			if err == nil {
+				return
+			}
+			for _, want := range append([]string{"RDS Proxy", tt.secret}, tt.wantMissing...) {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("err %q doesn't contain %q", err, want)
+				}
+			}
+		})
+	}
+
+	if _, err := GetRDSProxyCredential(sess, "missing"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("err = %v, want ErrSecretNotFound", err)
+	}
+}
@@ -0,0 +1,88 @@
+package awssecret
+
+import (
+	"fmt"
+	"strings"
+)
+
+// redactedValue replaces secret fields in redacted credentials.
+const redactedValue = "****"
+
+func redact(s string) string {
+	if s == "" {
+		return ""
+	}
+	return redactedValue
+}
+
+// redactPartial redacts s like redact, but keeps its first and last two
+// characters so that an identifier such as an API key can still be told
+// apart in logs. Values too short to show that much are fully redacted.
+//
+func redactPartial(s string) string {
+	if len(s) < 8 {
+		return redact(s)
+	}
+	return s[:2] + redactedValue + s[len(s)-2:]
+}
+
+// Redacted returns a copy of the credential with its Key and Password
+// replaced by "****", suitable for logging.
+//
+func (c Credential) Redacted() Credential {
+	c.Key = redact(c.Key)
+	c.Password = redact(c.Password)
+	return c
+}
+
+// String implements fmt.Stringer, formatting the redacted credential so
+// that printing a Credential never reveals its secrets.
+//
+func (c Credential) String() string {
+	type plain Credential
+	return fmt.Sprintf("%+v", plain(c.Redacted()))
+}
+
+// GoString implements fmt.GoStringer, so that %#v is also redacted.
+//
+func (c Credential) GoString() string {
+	type plain Credential
+	return "awssecret.Credential" + strings.TrimPrefix(fmt.Sprintf("%#v", plain(c.Redacted())), "awssecret.plain")
+}
+
+// Redacted returns a copy of the credential with its APISecret replaced by
+// "****" and its APIKey reduced to its first and last two characters (e.g.
+// "AK****42"), suitable for logging.
+//
+func (c APICredential) Redacted() APICredential {
+	c.APIKey = redactPartial(c.APIKey)
+	c.APISecret = redact(c.APISecret)
+	return c
+}
+
+// String implements fmt.Stringer, formatting the redacted credential so
+// that printing an APICredential never reveals its key or secret.
+//
+func (c APICredential) String() string {
+	type plain APICredential
+	return fmt.Sprintf("%+v", plain(c.Redacted()))
+}
+
+// GoString implements fmt.GoStringer, so that %#v is also redacted.
+//
+func (c APICredential) GoString() string {
+	type plain APICredential
+	return "awssecret.APICredential" + strings.TrimPrefix(fmt.Sprintf("%#v", plain(c.Redacted())), "awssecret.plain")
+}
+
+// EqualNonSecret reports whether c and other have the same engine, host,
+// port, username and database name, ignoring their Key and Password. This
+// detects a changed connection target without comparing secrets.
+//
+func (c Credential) EqualNonSecret(other Credential) bool {
+	return c.Engine == other.Engine &&
+		c.Host == other.Host &&
+		c.Port == other.Port &&
+		c.Username == other.Username &&
+		c.DBName == other.DBName
+}
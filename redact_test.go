@@ -0,0 +1,129 @@
+package awssecret
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestCredentialRedacted(t *testing.T) {
+	cred := Credential{Engine: "postgres", Host: "db.local", Port: 5432, Key: "s3cr3tkey", Username: "admin", Password: "hunter2", DBName: "app"}
+
+	got := cred.Redacted()
+	want := Credential{Engine: "postgres", Host: "db.local", Port: 5432, Key: "****", Username: "admin", Password: "****", DBName: "app"}
+	if got != want {
+		t.Errorf("Redacted() = %#v, want %#v", got, want)
+	}
+	if cred.Password != "hunter2" {
+		t.Error("Redacted() modified the original credential")
+	}
+
+	if empty := (Credential{Username: "admin"}).Redacted(); empty.Password != "" || empty.Key != "" {
+		t.Errorf("Redacted() of empty secrets = %#v, want them left empty", empty)
+	}
+}
+
+func TestAPICredentialRedacted(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"AKIAEXAMPLEKEY42", "AK****42"},
+		{"ab-12345", "ab****45"},
+		{"key-id", "****"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			cred := APICredential{BaseURL: "https://api.example.com", APIKey: tt.key, APISecret: "s3cr3t"}
+
+			got := cred.Redacted()
+			want := APICredential{BaseURL: "https://api.example.com", APIKey: tt.want, APISecret: "****"}
+			if got != want {
+				t.Errorf("Redacted() = %#v, want %#v", got, want)
+			}
+			if cred.APIKey != tt.key {
+				t.Error("Redacted() modified the original credential")
+			}
+		})
+	}
+}
+
+func TestAPICredentialFormattingHidesKey(t *testing.T) {
+	const key = "AKIAEXAMPLEKEY42"
+	c := APICredential{BaseURL: "https://api.example.com", APIKey: key, APISecret: "s3cr3t"}
+
+	for _, format := range []string{"%v", "%+v", "%#v", "%s"} {
+		if got := fmt.Sprintf(format, c); strings.Contains(got, key) {
+			t.Errorf("%s: %q reveals the API key", format, got)
+		}
+	}
+}
+
+func TestCredentialFormattingIsRedacted(t *testing.T) {
+	cred := Credential{Host: "db.local", Key: "s3cr3tkey", Username: "admin", Password: "hunter2"}
+	api := APICredential{BaseURL: "https://api.example.com", APIKey: "AKIAEXAMPLEKEY42", APISecret: "s3cr3t"}
+
+	tests := []struct {
+		format string
+		value  interface{}
+		secret string
+		want   string
+	}{
+		{"%v", cred, "hunter2", "admin"},
+		{"%+v", cred, "hunter2", "Username:admin"},
+		{"%#v", cred, "hunter2", "awssecret.Credential{"},
+		{"%s", cred, "s3cr3tkey", "db.local"},
+		{"%v", &cred, "hunter2", "admin"},
+		{"%v", []Credential{cred}, "hunter2", "admin"},
+		{"%v", api, "s3cr3t", "AK****42"},
+		{"%+v", api, "AKIAEXAMPLEKEY42", "APIKey:AK****42"},
+		{"%#v", api, "s3cr3t", "awssecret.APICredential{"},
+		{"%v", &api, "AKIAEXAMPLEKEY42", "AK****42"},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s %T", tt.format, tt.value), func(t *testing.T) {
+			got := fmt.Sprintf(tt.format, tt.value)
+			if strings.Contains(got, tt.secret) {
+				t.Errorf("%q reveals %q", got, tt.secret)
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("%q doesn't contain %q", got, tt.want)
+			}
+			if !strings.Contains(got, "****") {
+				t.Errorf("%q doesn't contain the redaction marker", got)
+			}
+		})
+	}
+}
+
+func TestCredentialEqualNonSecret(t *testing.T) {
+	base := Credential{Engine: "postgres", Host: "db.local", Port: 5432, Key: "k", Username: "admin", Password: "pw", DBName: "app"}
+
+	tests := []struct {
+		name   string
+		change func(*Credential)
+		want   bool
+	}{
+		{"identical", func(*Credential) {}, true},
+		{"password rotated", func(c *Credential) { c.Password = "rotated" }, true},
+		{"key rotated", func(c *Credential) { c.Key = "rotated" }, true},
+		{"engine", func(c *Credential) { c.Engine = "mysql" }, false},
+		{"host", func(c *Credential) { c.Host = "replica.local" }, false},
+		{"port", func(c *Credential) { c.Port = 5433 }, false},
+		{"username", func(c *Credential) { c.Username = "readonly" }, false},
+		{"dbname", func(c *Credential) { c.DBName = "other" }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			other := base
+			tt.change(&other)
+			if got := base.EqualNonSecret(other); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			if got := other.EqualNonSecret(base); got != tt.want {
+				t.Errorf("reversed: got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package awssecret
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/pkg/errors"
+)
+
+type redisSecret struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	Password string `json:"password"`
+	TLS      bool   `json:"tls"`
+	DB       int    `json:"db"`
+}
+
+// GetRedisURLSecret retrieves the named secret from AWS Secrets Manager
+// and converts it from JSON into a Redis connection URL of the form
+// redis://:password@host:port/db. The rediss:// scheme is used when the
+// JSON has "tls": true, and the database index defaults to 0 unless a "db"
+// field is present.
+//
+func GetRedisURLSecret(sess *session.Session, secretName string) (urlStr string, err error) {
+	return GetRedisURLSecretWithContext(context.Background(), sess, secretName)
+}
+
+// GetRedisURLSecretWithContext is the same as GetRedisURLSecret, but
+// uses the supplied context for the underlying AWS request.
+//
+func GetRedisURLSecretWithContext(ctx context.Context, sess *session.Session, secretName string) (urlStr string, err error) {
+	str, err := GetStringSecretWithContext(ctx, sess, secretName)
+	if err != nil {
+		return "", errors.Wrapf(err, "Couldn't build URL. Failed to retrieve secret")
+	}
+
+	r := redisSecret{}
+	err = Unmarshal([]byte(str), &r)
+	if err != nil {
+		return "", errors.Wrapf(err, "Couldn't build URL. Failed to decode JSON")
+	}
+
+	u := url.URL{
+		Scheme: "redis",
+		Host:   r.Host,
+		Path:   "/" + strconv.Itoa(r.DB),
+	}
+	if r.TLS {
+		u.Scheme = "rediss"
+	}
+	if r.Port != 0 {
+		u.Host = net.JoinHostPort(r.Host, strconv.Itoa(r.Port))
+	}
+	if r.Password != "" {
+		u.User = url.UserPassword("", r.Password)
+	}
+
+	return u.String(), nil
+}
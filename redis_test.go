@@ -0,0 +1,32 @@
+package awssecret
+
+import "testing"
+
+func TestGetRedisURLSecret(t *testing.T) {
+	tests := []struct {
+		name    string
+		secret  string
+		want    string
+		wantErr bool
+	}{
+		{"full", `{"host":"cache.local","port":6379,"password":"pw","db":2}`, "redis://:pw@cache.local:6379/2", false},
+		{"defaults", `{"host":"cache.local"}`, "redis://cache.local/0", false},
+		{"tls", `{"host":"cache.local","port":6380,"tls":true}`, "rediss://cache.local:6380/0", false},
+		{"password is escaped", `{"host":"cache.local","password":"p@ss/w:rd"}`, "redis://:p%40ss%2Fw%3Ard@cache.local/0", false},
+		{"ipv6 host", `{"host":"::1","port":6379}`, "redis://[::1]:6379/0", false},
+		{"not json", `redis://cache.local`, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sess := newStringSecretsSession(t, map[string]string{"cache": tt.secret})
+
+			got, err := GetRedisURLSecret(sess, "cache")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
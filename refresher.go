@@ -0,0 +1,197 @@
+package awssecret
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/pkg/errors"
+)
+
+// newTicker returns a channel which receives a tick every d, and a function
+// which stops it. It is replaceable in tests.
+var newTicker = func(d time.Duration) (<-chan time.Time, func()) {
+	t := time.NewTicker(d)
+	return t.C, t.Stop
+}
+
+// Refresher holds the value of a secret and periodically reloads it in the
+// background, so that long-running processes pick up rotated credentials
+// without restarting. It is safe for concurrent use.
+//
+type Refresher struct {
+	client     SecretsManagerAPI
+	secretName string
+	onChange   func(value string)
+	opts       RefresherOptions
+
+	mu       sync.RWMutex
+	value    string
+	checksum string
+	err      error
+
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// RefresherOptions configures a Refresher.
+//
+type RefresherOptions struct {
+	// CompareChecksums detects a changed value by comparing the SHA-256
+	// checksums of successive reloads, as returned by Checksum, rather than
+	// the values themselves. Comparing values is usually cheaper, since
+	// each reload retrieves the full value either way.
+	CompareChecksums bool
+}
+
+// NewRefresher retrieves the named secret and starts a goroutine which
+// reloads it every interval until Stop is called. If onChange is not nil, it
+// is called from that goroutine with the new value whenever a reload returns
+// a value which differs from the previous one. An error is returned if
+// interval isn't positive or the initial retrieval fails.
+//
+func NewRefresher(sess *session.Session, secretName string, interval time.Duration, onChange func(value string)) (r *Refresher, err error) {
+	return NewRefresherWithOptions(sess, secretName, interval, onChange, RefresherOptions{})
+}
+
+// NewRefresherWithClient is the same as NewRefresher, but retrieves the
+// secret using the supplied client.
+//
+func NewRefresherWithClient(client SecretsManagerAPI, secretName string, interval time.Duration, onChange func(value string)) (r *Refresher, err error) {
+	return NewRefresherWithOptionsWithClient(client, secretName, interval, onChange, RefresherOptions{})
+}
+
+// NewRefresherWithOptions is the same as NewRefresher, but configured by
+// opts.
+//
+func NewRefresherWithOptions(sess *session.Session, secretName string, interval time.Duration, onChange func(value string), opts RefresherOptions) (r *Refresher, err error) {
+	c := clientFor(sess)
+	if c.err != nil {
+		return nil, c.err
+	}
+	return NewRefresherWithOptionsWithClient(c.api, secretName, interval, onChange, opts)
+}
+
+// NewRefresherWithOptionsWithClient is the same as NewRefresherWithOptions,
+// but retrieves the secret using the supplied client.
+//
+func NewRefresherWithOptionsWithClient(client SecretsManagerAPI, secretName string, interval time.Duration, onChange func(value string), opts RefresherOptions) (r *Refresher, err error) {
+	err = validateInterval(interval)
+	if err != nil {
+		return nil, err
+	}
+
+	r = &Refresher{
+		client:     client,
+		secretName: secretName,
+		onChange:   onChange,
+		opts:       opts,
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+
+	r.value, err = GetStringSecretWithClient(client, secretName)
+	if err != nil {
+		return nil, err
+	}
+	if opts.CompareChecksums {
+		r.checksum = secretChecksum([]byte(r.value))
+	}
+
+	go r.run(interval)
+	return r, nil
+}
+
+// Get returns the most recently retrieved value of the secret.
+//
+func (r *Refresher) Get() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.value
+}
+
+// Checksum returns the SHA-256 checksum of the value returned by Get, in
+// the same form as GetSecretChecksum.
+//
+func (r *Refresher) Checksum() string {
+	return secretChecksum([]byte(r.Get()))
+}
+
+// Err returns the error from the most recent reload, or nil if it
+// succeeded. A failed reload leaves the previous value in place.
+//
+func (r *Refresher) Err() error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.err
+}
+
+// Stop ends the background reloading and waits for the goroutine to exit.
+// It is safe to call more than once.
+//
+func (r *Refresher) Stop() {
+	r.stopOnce.Do(func() { close(r.stop) })
+	<-r.done
+}
+
+func (r *Refresher) run(interval time.Duration) {
+	defer close(r.done)
+
+	ticks, stopTicker := newTicker(interval)
+	defer stopTicker()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-r.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticks:
+			r.refresh(ctx)
+		}
+	}
+}
+
+func (r *Refresher) refresh(ctx context.Context) {
+	value, err := GetStringSecretWithClientContext(ctx, r.client, r.secretName)
+	var checksum string
+	if err == nil && r.opts.CompareChecksums {
+		checksum = secretChecksum([]byte(value))
+	}
+
+	r.mu.Lock()
+	r.err = err
+	changed := err == nil && value != r.value
+	if r.opts.CompareChecksums {
+		changed = err == nil && checksum != r.checksum
+	}
+	if changed {
+		r.value = value
+		r.checksum = checksum
+	}
+	r.mu.Unlock()
+
+	if changed && r.onChange != nil {
+		r.onChange(value)
+	}
+}
+
+// validateInterval checks a refresh interval before it reaches
+// time.NewTicker, which panics on non-positive durations.
+//
+func validateInterval(interval time.Duration) error {
+	if interval <= 0 {
+		return errors.Errorf("Refresh interval must be positive, got %s", interval)
+	}
+	return nil
+}
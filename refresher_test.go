@@ -0,0 +1,153 @@
+package awssecret
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+)
+
+// fakeTicker replaces newTicker for the rest of the test. Ticks are sent on
+// the returned channel, and the interval each ticker was created with is
+// recorded in *interval. The channel is unbuffered, so a send only completes
+// once the refresher has finished handling the previous tick.
+func fakeTicker(t *testing.T) (ticks chan time.Time, interval *time.Duration) {
+	t.Helper()
+	ticks = make(chan time.Time)
+	interval = new(time.Duration)
+	previous := newTicker
+	newTicker = func(d time.Duration) (<-chan time.Time, func()) {
+		*interval = d
+		return ticks, func() {}
+	}
+	t.Cleanup(func() { newTicker = previous })
+	return ticks, interval
+}
+
+// sequenceStub returns each of values in turn, repeating the last one, or
+// fails with err while it is set.
+type sequenceStub struct {
+	mu     sync.Mutex
+	values []string
+	err    error
+}
+
+func (s *sequenceStub) GetSecretValue(*secretsmanager.GetSecretValueInput) (*secretsmanager.GetSecretValueOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return nil, s.err
+	}
+	v := s.values[0]
+	if len(s.values) > 1 {
+		s.values = s.values[1:]
+	}
+	return &secretsmanager.GetSecretValueOutput{SecretString: aws.String(v)}, nil
+}
+
+func (s *sequenceStub) setErr(err error) {
+	s.mu.Lock()
+	s.err = err
+	s.mu.Unlock()
+}
+
+func TestRefresherReloadsOnEachTick(t *testing.T) {
+	tests := []struct {
+		name string
+		opts RefresherOptions
+	}{
+		{"compare values", RefresherOptions{}},
+		{"compare checksums", RefresherOptions{CompareChecksums: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ticks, interval := fakeTicker(t)
+			stub := &sequenceStub{values: []string{"v1", "v1", "v2"}}
+
+			var changes []string
+			r, err := NewRefresherWithOptionsWithClient(stub, "db", time.Minute, func(v string) { changes = append(changes, v) }, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.Get() != "v1" {
+				t.Errorf("initial value = %q, want v1", r.Get())
+			}
+
+			defer r.Stop()
+
+			// The third send waits for the second reload to complete.
+			ticks <- time.Now()
+			ticks <- time.Now()
+			ticks <- time.Now()
+
+			if *interval != time.Minute {
+				t.Errorf("ticker interval = %s, want 1m", *interval)
+			}
+			if r.Get() != "v2" || r.Err() != nil {
+				t.Errorf("Get() = %q, Err() = %v; want v2, nil", r.Get(), r.Err())
+			}
+			if len(changes) != 1 || changes[0] != "v2" {
+				t.Errorf("onChange calls = %v, want only [v2]", changes)
+			}
+			if r.Checksum() != secretChecksum([]byte("v2")) {
+				t.Errorf("Checksum() = %q, want the checksum of v2", r.Checksum())
+			}
+		})
+	}
+}
+
+func TestRefresherKeepsValueOnError(t *testing.T) {
+	ticks, _ := fakeTicker(t)
+	stub := &sequenceStub{values: []string{"v1"}}
+
+	r, err := NewRefresherWithClient(stub, "db", time.Minute, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Stop()
+
+	stub.setErr(errors.New("throttled"))
+	ticks <- time.Now()
+	ticks <- time.Now()
+
+	if r.Get() != "v1" {
+		t.Errorf("Get() = %q, want the previous value", r.Get())
+	}
+	if r.Err() == nil {
+		t.Error("Err() = nil, want the failed reload's error")
+	}
+}
+
+func TestNewRefresherWithClientErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		client   SecretsManagerAPI
+		interval time.Duration
+	}{
+		{"zero interval", newStringStub(map[string]string{"db": "v"}), 0},
+		{"negative interval", newStringStub(map[string]string{"db": "v"}), -time.Second},
+		{"initial fetch fails", newStringStub(nil), time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewRefresherWithClient(tt.client, "db", tt.interval, nil)
+			if err == nil {
+				r.Stop()
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
+
+func TestRefresherStopIsIdempotent(t *testing.T) {
+	fakeTicker(t)
+	r, err := NewRefresherWithClient(newStringStub(map[string]string{"db": "v"}), "db", time.Minute, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Stop()
+	r.Stop()
+}
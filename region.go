@@ -0,0 +1,126 @@
+package awssecret
+
+import (
+	"context"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+)
+
+// GetStringSecretInRegion retrieves the named secret from AWS Secrets
+// Manager in the given region, regardless of the session's own region.
+//
+func GetStringSecretInRegion(sess *session.Session, secretName, region string) (secret string, err error) {
+	return GetStringSecretInRegionWithContext(context.Background(), sess, secretName, region)
+}
+
+// GetStringSecretInRegionWithContext is the same as GetStringSecretInRegion,
+// but uses the supplied context for the underlying AWS request.
+//
+func GetStringSecretInRegionWithContext(ctx context.Context, sess *session.Session, secretName, region string) (secret string, err error) {
+	client, err := NewSecretsManagerClientWithOptions(sess, ClientOptions{Region: region})
+	if err != nil {
+		return "", err
+	}
+	return GetStringSecretWithClientContext(ctx, client, secretName)
+}
+
+// arnRegionClient is the Secrets Manager client built by newService. It
+// sends requests to its configured region, except that requests naming a
+// secret by an ARN in another region are sent to a client for that region
+// instead, so that cross-region ARNs don't fail with
+// ResourceNotFoundException. Friendly names always use the configured
+// region, as does every request when ClientOptions.Region was set
+// explicitly. Requests which don't name a secret use the embedded client.
+//
+type arnRegionClient struct {
+	*secretsmanager.SecretsManager
+
+	sess   *session.Session
+	cfg    *aws.Config
+	pinned bool
+
+	mu       sync.Mutex
+	regional map[string]*secretsmanager.SecretsManager
+}
+
+func newARNRegionClient(sess *session.Session, cfg *aws.Config, pinned bool) *arnRegionClient {
+	return &arnRegionClient{
+		SecretsManager: secretsmanager.New(sess, cfg),
+		sess:           sess,
+		cfg:            cfg,
+		pinned:         pinned,
+		regional:       make(map[string]*secretsmanager.SecretsManager),
+	}
+}
+
+// GetSecretValue implements SecretsManagerAPI.
+//
+func (c *arnRegionClient) GetSecretValue(input *secretsmanager.GetSecretValueInput) (*secretsmanager.GetSecretValueOutput, error) {
+	return c.GetSecretValueWithContext(context.Background(), input)
+}
+
+// GetSecretValueWithContext is the same as GetSecretValue, but uses the
+// supplied context for the AWS request.
+//
+func (c *arnRegionClient) GetSecretValueWithContext(ctx context.Context, input *secretsmanager.GetSecretValueInput, opts ...request.Option) (*secretsmanager.GetSecretValueOutput, error) {
+	return c.serviceFor(aws.StringValue(input.SecretId)).GetSecretValueWithContext(ctx, input, opts...)
+}
+
+// DescribeSecretWithContext implements DescribeSecretAPI.
+//
+func (c *arnRegionClient) DescribeSecretWithContext(ctx context.Context, input *secretsmanager.DescribeSecretInput, opts ...request.Option) (*secretsmanager.DescribeSecretOutput, error) {
+	return c.serviceFor(aws.StringValue(input.SecretId)).DescribeSecretWithContext(ctx, input, opts...)
+}
+
+// PutSecretValueWithContext implements part of SecretsManagerWriteAPI.
+//
+func (c *arnRegionClient) PutSecretValueWithContext(ctx context.Context, input *secretsmanager.PutSecretValueInput, opts ...request.Option) (*secretsmanager.PutSecretValueOutput, error) {
+	return c.serviceFor(aws.StringValue(input.SecretId)).PutSecretValueWithContext(ctx, input, opts...)
+}
+
+// UpdateSecretVersionStageWithContext implements part of PromotePendingAPI.
+//
+func (c *arnRegionClient) UpdateSecretVersionStageWithContext(ctx context.Context, input *secretsmanager.UpdateSecretVersionStageInput, opts ...request.Option) (*secretsmanager.UpdateSecretVersionStageOutput, error) {
+	return c.serviceFor(aws.StringValue(input.SecretId)).UpdateSecretVersionStageWithContext(ctx, input, opts...)
+}
+
+// ListSecretVersionIdsWithContext implements ListSecretVersionIdsAPI.
+//
+func (c *arnRegionClient) ListSecretVersionIdsWithContext(ctx context.Context, input *secretsmanager.ListSecretVersionIdsInput, opts ...request.Option) (*secretsmanager.ListSecretVersionIdsOutput, error) {
+	return c.serviceFor(aws.StringValue(input.SecretId)).ListSecretVersionIdsWithContext(ctx, input, opts...)
+}
+
+// BatchGetSecretValueWithContext implements BatchGetSecretValueAPI. The
+// request is routed by its first secret ID, so GetStringSecretsBatch groups
+// IDs by region before batching them.
+//
+func (c *arnRegionClient) BatchGetSecretValueWithContext(ctx context.Context, input *secretsmanager.BatchGetSecretValueInput, opts ...request.Option) (*secretsmanager.BatchGetSecretValueOutput, error) {
+	var first string
+	if len(input.SecretIdList) > 0 {
+		first = aws.StringValue(input.SecretIdList[0])
+	}
+	return c.serviceFor(first).BatchGetSecretValueWithContext(ctx, input, opts...)
+}
+
+// serviceFor returns the client to use for secretID, creating a client for
+// the ARN's region the first time one is needed.
+//
+func (c *arnRegionClient) serviceFor(secretID string) *secretsmanager.SecretsManager {
+	region := secretARNRegion(secretID)
+	if c.pinned || region == "" || region == aws.StringValue(c.SecretsManager.Config.Region) {
+		return c.SecretsManager
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	svc, ok := c.regional[region]
+	if !ok {
+		svc = secretsmanager.New(c.sess, c.cfg.Copy().WithRegion(region))
+		c.regional[region] = svc
+	}
+	return svc
+}
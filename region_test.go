@@ -0,0 +1,163 @@
+package awssecret
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+const euARN = "arn:aws:secretsmanager:eu-west-1:123456789012:secret:prod/db-AbCdEf"
+
+func TestSecretARNRegion(t *testing.T) {
+	tests := []struct {
+		secretID string
+		want     string
+	}{
+		{euARN, "eu-west-1"},
+		{"arn:aws:secretsmanager:us-east-2:123456789012:secret:x", "us-east-2"},
+		{"prod/db", ""},
+		{"arn:aws:s3:::bucket", ""},
+	}
+	for _, tt := range tests {
+		if got := secretARNRegion(tt.secretID); got != tt.want {
+			t.Errorf("secretARNRegion(%q) = %q, want %q", tt.secretID, got, tt.want)
+		}
+	}
+}
+
+func TestNewServiceRoutesARNsByRegion(t *testing.T) {
+	svc, err := newService(testSession(t), ClientOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		secretID string
+		want     string
+	}{
+		{"prod/db", "us-east-1"},
+		{euARN, "eu-west-1"},
+		{"arn:aws:secretsmanager:us-east-1:123456789012:secret:prod/db-AbCdEf", "us-east-1"},
+	}
+	for _, tt := range tests {
+		got := aws.StringValue(svc.serviceFor(tt.secretID).Config.Region)
+		if got != tt.want {
+			t.Errorf("region for %q = %q, want %q", tt.secretID, got, tt.want)
+		}
+	}
+
+	if svc.serviceFor(euARN) != svc.serviceFor(euARN) {
+		t.Error("regional client was not reused")
+	}
+}
+
+func TestNewServiceExplicitRegionIsPinned(t *testing.T) {
+	svc, err := newService(testSession(t), ClientOptions{Region: "ap-south-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"prod/db", euARN} {
+		if got := aws.StringValue(svc.serviceFor(id).Config.Region); got != "ap-south-1" {
+			t.Errorf("region for %q = %q, want ap-south-1", id, got)
+		}
+	}
+}
+
+func TestBatchChunksGroupsByRegion(t *testing.T) {
+	names := []string{"a", euARN, "b"}
+	for i := 0; i < maxBatchSize; i++ {
+		names = append(names, "n")
+	}
+
+	chunks := batchChunks(names)
+	if len(chunks) != 3 {
+		t.Fatalf("got %d chunks, want 3: %v", len(chunks), chunks)
+	}
+	if len(chunks[0]) != maxBatchSize || chunks[0][0] != "a" || chunks[0][1] != "b" {
+		t.Errorf("first chunk = %v", chunks[0])
+	}
+	if len(chunks[1]) != 2 {
+		t.Errorf("second chunk = %v, want the remaining friendly names", chunks[1])
+	}
+	if len(chunks[2]) != 1 || chunks[2][0] != euARN {
+		t.Errorf("third chunk = %v, want only the eu-west-1 ARN", chunks[2])
+	}
+}
+
+// hostRecorder is an http.RoundTripper which answers every request with a
+// GetSecretValue result, recording the host each was sent to.
+type hostRecorder struct {
+	mu    sync.Mutex
+	hosts []string
+}
+
+func (r *hostRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.mu.Lock()
+	r.hosts = append(r.hosts, req.URL.Host)
+	r.mu.Unlock()
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/x-amz-json-1.1"}},
+		Body:       io.NopCloser(strings.NewReader(`{"Name":"db","SecretString":"hunter2"}`)),
+		Request:    req,
+	}, nil
+}
+
+func (r *hostRecorder) last() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.hosts[len(r.hosts)-1]
+}
+
+func TestGetStringSecretInRegion(t *testing.T) {
+	recorder := &hostRecorder{}
+	sess := testSession(t).Copy(aws.NewConfig().WithHTTPClient(&http.Client{Transport: recorder}))
+
+	tests := []struct {
+		region   string
+		secretID string
+		wantHost string
+	}{
+		{"us-west-2", "db", "secretsmanager.us-west-2.amazonaws.com"},
+		{"eu-central-1", "db", "secretsmanager.eu-central-1.amazonaws.com"},
+		{"us-west-2", euARN, "secretsmanager.us-west-2.amazonaws.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.region+"/"+tt.secretID, func(t *testing.T) {
+			got, err := GetStringSecretInRegion(sess, tt.secretID, tt.region)
+			if err != nil || got != "hunter2" {
+				t.Fatalf("got %q, %v", got, err)
+			}
+			if host := recorder.last(); host != tt.wantHost {
+				t.Errorf("request sent to %q, want %q", host, tt.wantHost)
+			}
+		})
+	}
+}
+
+func TestNewClientReadsARNsFromTheirRegion(t *testing.T) {
+	recorder := &hostRecorder{}
+	c := NewClient(testSession(t).Copy(aws.NewConfig().WithHTTPClient(&http.Client{Transport: recorder})))
+
+	tests := []struct {
+		secretID string
+		wantHost string
+	}{
+		{"db", "secretsmanager.us-east-1.amazonaws.com"},
+		{euARN, "secretsmanager.eu-west-1.amazonaws.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.secretID, func(t *testing.T) {
+			if _, err := c.GetString(tt.secretID); err != nil {
+				t.Fatal(err)
+			}
+			if host := recorder.last(); host != tt.wantHost {
+				t.Errorf("request sent to %q, want %q", host, tt.wantHost)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package awssecret
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+// ReplicaStatus describes the replication of a secret to one region.
+//
+type ReplicaStatus struct {
+	Region string
+
+	// Status is one of the secretsmanager.StatusType values: InSync,
+	// Failed or InProgress.
+	Status        string
+	StatusMessage string
+	KMSKeyID      string
+
+	LastAccessedDate time.Time
+}
+
+// ReplicationStatus returns the status of each region the named secret is
+// replicated to. It is empty if the secret isn't replicated.
+//
+func ReplicationStatus(sess *session.Session, secretName string) (replicas []ReplicaStatus, err error) {
+	return ReplicationStatusWithContext(context.Background(), sess, secretName)
+}
+
+// ReplicationStatusWithContext is the same as ReplicationStatus, but uses
+// the supplied context for the underlying AWS request.
+//
+func ReplicationStatusWithContext(ctx context.Context, sess *session.Session, secretName string) (replicas []ReplicaStatus, err error) {
+	svc, err := newService(sess, ClientOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return ReplicationStatusWithClientContext(ctx, svc, secretName)
+}
+
+// ReplicationStatusWithClientContext is the same as
+// ReplicationStatusWithContext, but uses the supplied client.
+//
+func ReplicationStatusWithClientContext(ctx context.Context, client DescribeSecretAPI, secretName string) (replicas []ReplicaStatus, err error) {
+	result, err := describeSecret(ctx, client, secretName)
+	if err != nil {
+		return nil, err
+	}
+
+	replicas = make([]ReplicaStatus, 0, len(result.ReplicationStatus))
+	for _, r := range result.ReplicationStatus {
+		replicas = append(replicas, ReplicaStatus{
+			Region:           aws.StringValue(r.Region),
+			Status:           aws.StringValue(r.Status),
+			StatusMessage:    aws.StringValue(r.StatusMessage),
+			KMSKeyID:         aws.StringValue(r.KmsKeyId),
+			LastAccessedDate: aws.TimeValue(r.LastAccessedDate),
+		})
+	}
+	return replicas, nil
+}
@@ -0,0 +1,59 @@
+package awssecret
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+)
+
+func TestReplicationStatus(t *testing.T) {
+	accessed := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	stub := &describeStub{outputs: map[string]*secretsmanager.DescribeSecretOutput{
+		"local": {Name: aws.String("local")},
+		"replicated": {
+			Name: aws.String("replicated"),
+			ReplicationStatus: []*secretsmanager.ReplicationStatusType{
+				{
+					Region:           aws.String("eu-west-1"),
+					Status:           aws.String(secretsmanager.StatusTypeInSync),
+					KmsKeyId:         aws.String(testKMSKeyARN),
+					LastAccessedDate: aws.Time(accessed),
+				},
+				{
+					Region:        aws.String("ap-south-1"),
+					Status:        aws.String(secretsmanager.StatusTypeFailed),
+					StatusMessage: aws.String("Access denied to KMS key"),
+				},
+			},
+		},
+	}}
+
+	tests := []struct {
+		secret  string
+		want    []ReplicaStatus
+		wantErr error
+	}{
+		{"local", []ReplicaStatus{}, nil},
+		{"replicated", []ReplicaStatus{
+			{Region: "eu-west-1", Status: "InSync", KMSKeyID: testKMSKeyARN, LastAccessedDate: accessed},
+			{Region: "ap-south-1", Status: "Failed", StatusMessage: "Access denied to KMS key"},
+		}, nil},
+		{"missing", nil, ErrSecretNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.secret, func(t *testing.T) {
+			got, err := ReplicationStatusWithClientContext(context.Background(), stub, tt.secret)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
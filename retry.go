@@ -0,0 +1,118 @@
+package awssecret
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+)
+
+// RetryOptions configures how a RetryingClient retries failed requests.
+// Zero values use the defaults below.
+//
+type RetryOptions struct {
+	// MaxAttempts is the total number of attempts made, including the
+	// first. Defaults to DefaultMaxAttempts.
+	MaxAttempts int
+
+	// BaseDelay is the delay before the first retry, doubling with each
+	// subsequent retry. Defaults to DefaultBaseDelay.
+	BaseDelay time.Duration
+
+	// MaxDelay caps the delay between any two attempts. Defaults to
+	// DefaultMaxDelay.
+	MaxDelay time.Duration
+}
+
+// Defaults for RetryOptions.
+const (
+	DefaultMaxAttempts = 5
+	DefaultBaseDelay   = 100 * time.Millisecond
+	DefaultMaxDelay    = 5 * time.Second
+)
+
+// RetryingClient wraps a SecretsManagerAPI and retries GetSecretValue with
+// exponential backoff and jitter when AWS throttles the request or fails
+// with a 5xx error. All other errors, such as ResourceNotFoundException,
+// are returned immediately. It can be passed to any of the WithClient
+// functions. Clients which already retry through the SDK (see
+// ClientOptions.Config) don't need wrapping.
+//
+type RetryingClient struct {
+	client SecretsManagerAPI
+	opts   RetryOptions
+}
+
+// NewRetryingClient returns a RetryingClient which retries requests made
+// with client according to opts.
+//
+func NewRetryingClient(client SecretsManagerAPI, opts RetryOptions) *RetryingClient {
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = DefaultMaxAttempts
+	}
+	if opts.BaseDelay <= 0 {
+		opts.BaseDelay = DefaultBaseDelay
+	}
+	if opts.MaxDelay <= 0 {
+		opts.MaxDelay = DefaultMaxDelay
+	}
+	return &RetryingClient{client: client, opts: opts}
+}
+
+// GetSecretValue implements SecretsManagerAPI.
+//
+func (c *RetryingClient) GetSecretValue(input *secretsmanager.GetSecretValueInput) (*secretsmanager.GetSecretValueOutput, error) {
+	return c.GetSecretValueWithContext(context.Background(), input)
+}
+
+// GetSecretValueWithContext is the same as GetSecretValue, but stops
+// retrying as soon as the supplied context is done.
+//
+func (c *RetryingClient) GetSecretValueWithContext(ctx context.Context, input *secretsmanager.GetSecretValueInput, opts ...request.Option) (output *secretsmanager.GetSecretValueOutput, err error) {
+	for attempt := 1; ; attempt++ {
+		output, err = callGetSecretValue(ctx, c.client, input, opts...)
+		if err == nil || attempt >= c.opts.MaxAttempts || !isRetryable(err) {
+			return output, err
+		}
+
+		timer := time.NewTimer(c.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns a random delay of up to BaseDelay * 2^(attempt-1), capped
+// at MaxDelay.
+//
+func (c *RetryingClient) backoff(attempt int) time.Duration {
+	delay := c.opts.MaxDelay
+	if shift := uint(attempt - 1); shift < 32 {
+		if d := c.opts.BaseDelay << shift; d > 0 && d < delay {
+			delay = d
+		}
+	}
+	return time.Duration(rand.Int63n(int64(delay) + 1))
+}
+
+// isRetryable reports whether err is a throttling or transient server
+// error.
+//
+func isRetryable(err error) bool {
+	if request.IsErrorThrottle(err) {
+		return true
+	}
+	if rerr, ok := err.(awserr.RequestFailure); ok && rerr.StatusCode() >= 500 {
+		return true
+	}
+	if aerr, ok := err.(awserr.Error); ok {
+		return aerr.Code() == secretsmanager.ErrCodeInternalServiceError
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package awssecret
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+)
+
+// failingStub fails the first failures requests with err, then succeeds.
+func failingStub(err error, failures int) *stubClient {
+	s := &stubClient{}
+	s.get = func(*secretsmanager.GetSecretValueInput) (*secretsmanager.GetSecretValueOutput, error) {
+		if s.calls() <= failures {
+			return nil, err
+		}
+		return &secretsmanager.GetSecretValueOutput{SecretString: aws.String("ok")}, nil
+	}
+	return s
+}
+
+func TestRetryingClientAttempts(t *testing.T) {
+	throttled := awserr.New("ThrottlingException", "slow down", nil)
+	unavailable := awserr.NewRequestFailure(awserr.New("ServiceUnavailable", "down", nil), 503, "req-1")
+	internal := awserr.New(secretsmanager.ErrCodeInternalServiceError, "oops", nil)
+	notFound := awserr.New(secretsmanager.ErrCodeResourceNotFoundException, "not found", nil)
+	forbidden := awserr.NewRequestFailure(awserr.New("AccessDeniedException", "no", nil), 403, "req-2")
+
+	tests := []struct {
+		name      string
+		err       error
+		failures  int
+		wantCalls int
+		wantErr   bool
+	}{
+		{"success", nil, 0, 1, false},
+		{"throttled then success", throttled, 2, 3, false},
+		{"5xx then success", unavailable, 1, 2, false},
+		{"internal error then success", internal, 1, 2, false},
+		{"always throttled", throttled, 100, 4, true},
+		{"not found isn't retried", notFound, 100, 1, true},
+		{"4xx isn't retried", forbidden, 100, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := failingStub(tt.err, tt.failures)
+			c := NewRetryingClient(stub, RetryOptions{MaxAttempts: 4, BaseDelay: time.Nanosecond, MaxDelay: time.Nanosecond})
+
+			_, err := c.GetSecretValue(getInput("db"))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if n := stub.calls(); n != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", n, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRetryingClientStopsWhenContextIsDone(t *testing.T) {
+	stub := failingStub(awserr.New("ThrottlingException", "slow down", nil), 100)
+	c := NewRetryingClient(stub, RetryOptions{MaxAttempts: 10, BaseDelay: time.Hour, MaxDelay: time.Hour})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := c.GetSecretValueWithContext(ctx, getInput("db"))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want context.DeadlineExceeded", err)
+	}
+	if n := stub.calls(); n != 1 {
+		t.Errorf("calls = %d, want 1", n)
+	}
+}
+
+func TestRetryingClientBackoff(t *testing.T) {
+	c := NewRetryingClient(nil, RetryOptions{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second})
+
+	tests := []struct {
+		attempt int
+		max     time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{4, 800 * time.Millisecond},
+		{5, time.Second},
+		{64, time.Second},
+	}
+	for _, tt := range tests {
+		for i := 0; i < 100; i++ {
+			if d := c.backoff(tt.attempt); d < 0 || d > tt.max {
+				t.Fatalf("backoff(%d) = %s, want between 0 and %s", tt.attempt, d, tt.max)
+			}
+		}
+	}
+}
+
+func TestNewRetryingClientDefaults(t *testing.T) {
+	c := NewRetryingClient(nil, RetryOptions{})
+	want := RetryOptions{MaxAttempts: DefaultMaxAttempts, BaseDelay: DefaultBaseDelay, MaxDelay: DefaultMaxDelay}
+	if c.opts != want {
+		t.Errorf("opts = %+v, want %+v", c.opts, want)
+	}
+}
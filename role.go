@@ -0,0 +1,46 @@
+package awssecret
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+// GetStringSecretAsRole retrieves the named secret from AWS Secrets Manager
+// after assuming roleARN, which allows reading secrets held in another
+// account. The role is assumed using the default credentials from the
+// environment and shared config, so it fails with ErrNoSession after
+// SetRequireSession(true); use GetStringSecretAsRoleWithSession to assume the
+// role with an explicit session. Use NewSecretsManagerClientWithOptions to
+// supply an external ID or session name.
+//
+func GetStringSecretAsRole(secretName, roleARN string) (secret string, err error) {
+	return GetStringSecretAsRoleWithContext(context.Background(), secretName, roleARN)
+}
+
+// GetStringSecretAsRoleWithContext is the same as GetStringSecretAsRole, but
+// uses the supplied context for the underlying AWS request.
+//
+func GetStringSecretAsRoleWithContext(ctx context.Context, secretName, roleARN string) (secret string, err error) {
+	return GetStringSecretAsRoleWithSessionContext(ctx, nil, secretName, roleARN)
+}
+
+// GetStringSecretAsRoleWithSession is the same as GetStringSecretAsRole, but
+// assumes roleARN using the credentials of sess. A nil session falls back to
+// the default session, as with every other function in the package.
+//
+func GetStringSecretAsRoleWithSession(sess *session.Session, secretName, roleARN string) (secret string, err error) {
+	return GetStringSecretAsRoleWithSessionContext(context.Background(), sess, secretName, roleARN)
+}
+
+// GetStringSecretAsRoleWithSessionContext is the same as
+// GetStringSecretAsRoleWithSession, but uses the supplied context for the
+// underlying AWS request.
+//
+func GetStringSecretAsRoleWithSessionContext(ctx context.Context, sess *session.Session, secretName, roleARN string) (secret string, err error) {
+	client, err := NewSecretsManagerClientWithOptions(sess, ClientOptions{RoleARN: roleARN})
+	if err != nil {
+		return "", err
+	}
+	return GetStringSecretWithClientContext(ctx, client, secretName)
+}
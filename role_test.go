@@ -0,0 +1,101 @@
+package awssecret
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+const testRoleARN = "arn:aws:iam::123456789012:role/reader"
+
+// newDenyingSession returns a session whose requests, to any service, go to
+// a server which denies them. The form of each request is sent on the
+// returned channel, so tests can check which STS calls were made.
+func newDenyingSession(t *testing.T) (*session.Session, <-chan url.Values) {
+	t.Helper()
+	forms := make(chan url.Values, 100)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		forms <- r.Form
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`<ErrorResponse><Error><Code>AccessDenied</Code><Message>denied</Message></Error></ErrorResponse>`))
+	}))
+	t.Cleanup(server.Close)
+
+	sess, err := session.NewSession(aws.NewConfig().
+		WithRegion("us-east-1").
+		WithEndpoint(server.URL).
+		WithCredentials(credentials.NewStaticCredentials("id", "secret", "")).
+		WithMaxRetries(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sess, forms
+}
+
+func TestClientOptionsAssumeRole(t *testing.T) {
+	tests := []struct {
+		name            string
+		opts            ClientOptions
+		wantExternalID  string
+		wantSessionName string
+	}{
+		{"role only", ClientOptions{RoleARN: testRoleARN}, "", ""},
+		{"external ID", ClientOptions{RoleARN: testRoleARN, ExternalID: "ext-123"}, "ext-123", ""},
+		{"session name", ClientOptions{RoleARN: testRoleARN, RoleSessionName: "reader"}, "", "reader"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sess, forms := newDenyingSession(t)
+			client, err := NewSecretsManagerClientWithOptions(sess, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := GetStringSecretWithClient(client, "db"); err == nil {
+				t.Fatal("expected the denied AssumeRole error")
+			}
+
+			form := <-forms
+			if form.Get("Action") != "AssumeRole" || form.Get("RoleArn") != testRoleARN {
+				t.Fatalf("first request = %v, want AssumeRole of %s", form, testRoleARN)
+			}
+			if got := form.Get("ExternalId"); got != tt.wantExternalID {
+				t.Errorf("ExternalId = %q, want %q", got, tt.wantExternalID)
+			}
+			if got := form.Get("RoleSessionName"); tt.wantSessionName != "" && got != tt.wantSessionName {
+				t.Errorf("RoleSessionName = %q, want %q", got, tt.wantSessionName)
+			}
+		})
+	}
+}
+
+func TestGetStringSecretAsRoleRequiresSession(t *testing.T) {
+	SetRequireSession(true)
+	t.Cleanup(func() { SetRequireSession(false) })
+
+	_, err := GetStringSecretAsRole("db", testRoleARN)
+	if !errors.Is(err, ErrNoSession) {
+		t.Errorf("err = %v, want ErrNoSession", err)
+	}
+}
+
+func TestGetStringSecretAsRoleWithSession(t *testing.T) {
+	SetRequireSession(true)
+	t.Cleanup(func() { SetRequireSession(false) })
+
+	sess, forms := newDenyingSession(t)
+	_, err := GetStringSecretAsRoleWithSession(sess, "db", testRoleARN)
+	if err == nil || errors.Is(err, ErrNoSession) {
+		t.Errorf("err = %v, want the denied AssumeRole error", err)
+	}
+	if form := <-forms; form.Get("Action") != "AssumeRole" || form.Get("RoleArn") != testRoleARN {
+		t.Errorf("first request = %v, want AssumeRole of %s", form, testRoleARN)
+	}
+}
@@ -0,0 +1,67 @@
+package awssecret
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+// IsRotationEnabled reports whether automatic rotation is turned on for the
+// named secret.
+//
+func IsRotationEnabled(sess *session.Session, secretName string) (enabled bool, err error) {
+	return IsRotationEnabledWithContext(context.Background(), sess, secretName)
+}
+
+// IsRotationEnabledWithContext is the same as IsRotationEnabled, but uses
+// the supplied context for the underlying AWS request.
+//
+func IsRotationEnabledWithContext(ctx context.Context, sess *session.Session, secretName string) (enabled bool, err error) {
+	svc, err := newService(sess, ClientOptions{})
+	if err != nil {
+		return false, err
+	}
+	return IsRotationEnabledWithClientContext(ctx, svc, secretName)
+}
+
+// IsRotationEnabledWithClientContext is the same as
+// IsRotationEnabledWithContext, but uses the supplied client.
+//
+func IsRotationEnabledWithClientContext(ctx context.Context, client DescribeSecretAPI, secretName string) (enabled bool, err error) {
+	result, err := describeSecret(ctx, client, secretName)
+	if err != nil {
+		return false, err
+	}
+	return aws.BoolValue(result.RotationEnabled), nil
+}
+
+// NextRotationDate returns when the named secret is next scheduled to be
+// rotated. The zero time is returned if no rotation is scheduled.
+//
+func NextRotationDate(sess *session.Session, secretName string) (next time.Time, err error) {
+	return NextRotationDateWithContext(context.Background(), sess, secretName)
+}
+
+// NextRotationDateWithContext is the same as NextRotationDate, but uses the
+// supplied context for the underlying AWS request.
+//
+func NextRotationDateWithContext(ctx context.Context, sess *session.Session, secretName string) (next time.Time, err error) {
+	svc, err := newService(sess, ClientOptions{})
+	if err != nil {
+		return time.Time{}, err
+	}
+	return NextRotationDateWithClientContext(ctx, svc, secretName)
+}
+
+// NextRotationDateWithClientContext is the same as
+// NextRotationDateWithContext, but uses the supplied client.
+//
+func NextRotationDateWithClientContext(ctx context.Context, client DescribeSecretAPI, secretName string) (next time.Time, err error) {
+	result, err := describeSecret(ctx, client, secretName)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return aws.TimeValue(result.NextRotationDate), nil
+}
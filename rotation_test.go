@@ -0,0 +1,60 @@
+package awssecret
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+)
+
+func TestRotation(t *testing.T) {
+	next := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	stub := &describeStub{outputs: map[string]*secretsmanager.DescribeSecretOutput{
+		"enabled": {
+			Name:             aws.String("enabled"),
+			RotationEnabled:  aws.Bool(true),
+			NextRotationDate: aws.Time(next),
+		},
+		"disabled": {
+			Name:            aws.String("disabled"),
+			RotationEnabled: aws.Bool(false),
+		},
+		"never-configured": {
+			Name: aws.String("never-configured"),
+		},
+	}}
+
+	tests := []struct {
+		secret      string
+		wantEnabled bool
+		wantNext    time.Time
+		wantErr     error
+	}{
+		{"enabled", true, next, nil},
+		{"disabled", false, time.Time{}, nil},
+		{"never-configured", false, time.Time{}, nil},
+		{"missing", false, time.Time{}, ErrSecretNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.secret, func(t *testing.T) {
+			enabled, err := IsRotationEnabledWithClientContext(context.Background(), stub, tt.secret)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("IsRotationEnabled err = %v, want %v", err, tt.wantErr)
+			}
+			if enabled != tt.wantEnabled {
+				t.Errorf("IsRotationEnabled = %v, want %v", enabled, tt.wantEnabled)
+			}
+
+			got, err := NextRotationDateWithClientContext(context.Background(), stub, tt.secret)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NextRotationDate err = %v, want %v", err, tt.wantErr)
+			}
+			if !got.Equal(tt.wantNext) {
+				t.Errorf("NextRotationDate = %v, want %v", got, tt.wantNext)
+			}
+		})
+	}
+}
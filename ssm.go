@@ -0,0 +1,82 @@
+package awssecret
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/pkg/errors"
+)
+
+// SSMGetParameterAPI is the subset of the AWS Systems Manager client used to
+// read parameters from Parameter Store.
+//
+type SSMGetParameterAPI interface {
+	GetParameterWithContext(context.Context, *ssm.GetParameterInput, ...request.Option) (*ssm.GetParameterOutput, error)
+}
+
+// GetStringParameter retrieves the named parameter from SSM Parameter Store
+// and returns its value. SecureString parameters are only decrypted if
+// withDecryption is true. A missing parameter returns an error matching
+// ErrSecretNotFound.
+//
+func GetStringParameter(sess *session.Session, name string, withDecryption bool) (value string, err error) {
+	return GetStringParameterWithContext(context.Background(), sess, name, withDecryption)
+}
+
+// GetStringParameterWithContext is the same as GetStringParameter, but uses
+// the supplied context for the underlying AWS request.
+//
+func GetStringParameterWithContext(ctx context.Context, sess *session.Session, name string, withDecryption bool) (value string, err error) {
+	sess, err = sessionOrDefault(sess)
+	if err != nil {
+		return "", err
+	}
+	return GetStringParameterWithClientContext(ctx, ssm.New(sess), name, withDecryption)
+}
+
+// GetStringParameterWithClientContext is the same as
+// GetStringParameterWithContext, but uses the supplied client.
+//
+func GetStringParameterWithClientContext(ctx context.Context, client SSMGetParameterAPI, name string, withDecryption bool) (value string, err error) {
+	result, err := client.GetParameterWithContext(ctx, &ssm.GetParameterInput{
+		Name:           aws.String(name),
+		WithDecryption: aws.Bool(withDecryption),
+	})
+	if err != nil {
+		code := "Unknown error"
+		if aerr, ok := err.(awserr.Error); ok {
+			code = aerr.Code()
+		}
+		return "", errors.Wrapf(mapAWSError(name, err), "Failed to get parameter %q from SSM Parameter Store: %s", name, code)
+	}
+
+	if result.Parameter == nil || result.Parameter.Value == nil {
+		return "", errors.Errorf("Parameter %q has no value", name)
+	}
+
+	return *result.Parameter.Value, nil
+}
+
+// GetStringSecretOrParameter retrieves the named secret from AWS Secrets
+// Manager, falling back to a (decrypted) SSM Parameter Store parameter of
+// the same name if no such secret exists.
+//
+func GetStringSecretOrParameter(sess *session.Session, name string) (value string, err error) {
+	return GetStringSecretOrParameterWithContext(context.Background(), sess, name)
+}
+
+// GetStringSecretOrParameterWithContext is the same as
+// GetStringSecretOrParameter, but uses the supplied context for the
+// underlying AWS requests.
+//
+func GetStringSecretOrParameterWithContext(ctx context.Context, sess *session.Session, name string) (value string, err error) {
+	value, err = GetStringSecretWithContext(ctx, sess, name)
+	if errors.Is(err, ErrSecretNotFound) {
+		return GetStringParameterWithContext(ctx, sess, name, true)
+	}
+	return value, err
+}
@@ -0,0 +1,134 @@
+package awssecret
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ssm"
+)
+
+// ssmStub is a hand-rolled SSMGetParameterAPI answering from values.
+// Parameters whose names start with "secure/" are only readable with
+// decryption. Missing names get a ParameterNotFound error.
+type ssmStub struct {
+	values map[string]string
+	inputs []*ssm.GetParameterInput
+}
+
+func (s *ssmStub) GetParameterWithContext(ctx context.Context, input *ssm.GetParameterInput, opts ...request.Option) (*ssm.GetParameterOutput, error) {
+	s.inputs = append(s.inputs, input)
+	name := aws.StringValue(input.Name)
+	value, ok := s.values[name]
+	if !ok {
+		return nil, awserr.New(ssm.ErrCodeParameterNotFound, "not found", nil)
+	}
+	if name == "empty" {
+		return &ssm.GetParameterOutput{Parameter: &ssm.Parameter{Name: input.Name}}, nil
+	}
+	if len(name) > 7 && name[:7] == "secure/" && !aws.BoolValue(input.WithDecryption) {
+		value = "AQICAHh-ciphertext"
+	}
+	return &ssm.GetParameterOutput{Parameter: &ssm.Parameter{Name: input.Name, Value: aws.String(value)}}, nil
+}
+
+func TestGetStringParameter(t *testing.T) {
+	stub := &ssmStub{values: map[string]string{
+		"plain":       "value",
+		"secure/pass": "hunter2",
+		"empty":       "",
+	}}
+
+	tests := []struct {
+		name           string
+		param          string
+		withDecryption bool
+		want           string
+		wantErr        bool
+		wantNotFound   bool
+	}{
+		{"plain", "plain", false, "value", false, false},
+		{"decrypted", "secure/pass", true, "hunter2", false, false},
+		{"encrypted", "secure/pass", false, "AQICAHh-ciphertext", false, false},
+		{"no value", "empty", false, "", true, false},
+		{"missing", "missing", false, "", true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetStringParameterWithClientContext(context.Background(), stub, tt.param, tt.withDecryption)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if errors.Is(err, ErrSecretNotFound) != tt.wantNotFound {
+				t.Errorf("errors.Is(err, ErrSecretNotFound) = %v, want %v", !tt.wantNotFound, tt.wantNotFound)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			last := stub.inputs[len(stub.inputs)-1]
+			if aws.BoolValue(last.WithDecryption) != tt.withDecryption {
+				t.Errorf("WithDecryption = %v, want %v", aws.BoolValue(last.WithDecryption), tt.withDecryption)
+			}
+		})
+	}
+}
+
+func TestGetStringSecretOrParameter(t *testing.T) {
+	url, requests := newSecretsManagerServer(t, func(req smRequest) (int, interface{}) {
+		name, _ := req.Body["Name"].(string)
+		switch req.Operation {
+		case "GetSecretValue":
+			if req.Body["SecretId"] == "in-secrets-manager" {
+				return http.StatusOK, map[string]string{"Name": "in-secrets-manager", "SecretString": "from secrets manager"}
+			}
+			if req.Body["SecretId"] == "deleted" {
+				return http.StatusBadRequest, map[string]string{"__type": "InvalidRequestException", "message": "marked for deletion"}
+			}
+			return http.StatusBadRequest, map[string]string{"__type": "ResourceNotFoundException", "message": "not found"}
+		case "AmazonSSM.GetParameter":
+			if name == "in-ssm" && req.Body["WithDecryption"] == true {
+				return http.StatusOK, map[string]interface{}{"Parameter": map[string]string{"Name": name, "Value": "from ssm"}}
+			}
+			return http.StatusBadRequest, map[string]string{"__type": "ParameterNotFound", "message": "not found"}
+		}
+		return http.StatusBadRequest, map[string]string{"__type": "UnknownOperationException"}
+	})
+	sess := testSession(t).Copy(aws.NewConfig().WithEndpoint(url))
+
+	tests := []struct {
+		name       string
+		want       string
+		wantErr    error
+		wantLookup []string
+	}{
+		{"in-secrets-manager", "from secrets manager", nil, []string{"GetSecretValue"}},
+		{"in-ssm", "from ssm", nil, []string{"GetSecretValue", "AmazonSSM.GetParameter"}},
+		{"missing", "", ErrSecretNotFound, []string{"GetSecretValue", "AmazonSSM.GetParameter"}},
+		{"deleted", "", ErrInvalidRequest, []string{"GetSecretValue"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetStringSecretOrParameter(sess, tt.name)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			for _, want := range tt.wantLookup {
+				if req := <-requests; req.Operation != want {
+					t.Errorf("operation = %q, want %q", req.Operation, want)
+				}
+			}
+			select {
+			case req := <-requests:
+				t.Errorf("unexpected %s request", req.Operation)
+			default:
+			}
+		})
+	}
+}
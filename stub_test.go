@@ -0,0 +1,145 @@
+package awssecret
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+)
+
+// stubClient is a hand-rolled SecretsManagerAPI. get answers each request,
+// and every input is recorded.
+type stubClient struct {
+	get func(*secretsmanager.GetSecretValueInput) (*secretsmanager.GetSecretValueOutput, error)
+
+	mu     sync.Mutex
+	inputs []*secretsmanager.GetSecretValueInput
+}
+
+func (s *stubClient) GetSecretValue(input *secretsmanager.GetSecretValueInput) (*secretsmanager.GetSecretValueOutput, error) {
+	s.mu.Lock()
+	s.inputs = append(s.inputs, input)
+	s.mu.Unlock()
+	return s.get(input)
+}
+
+func (s *stubClient) calls() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.inputs)
+}
+
+func (s *stubClient) lastInput() *secretsmanager.GetSecretValueInput {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.inputs) == 0 {
+		return nil
+	}
+	return s.inputs[len(s.inputs)-1]
+}
+
+// newStringStub returns a stubClient serving values as SecretStrings, and
+// ResourceNotFoundException for any other name.
+func newStringStub(values map[string]string) *stubClient {
+	return &stubClient{get: func(input *secretsmanager.GetSecretValueInput) (*secretsmanager.GetSecretValueOutput, error) {
+		v, ok := values[aws.StringValue(input.SecretId)]
+		if !ok {
+			return nil, awserr.New(secretsmanager.ErrCodeResourceNotFoundException, "not found", nil)
+		}
+		return &secretsmanager.GetSecretValueOutput{
+			Name:         input.SecretId,
+			SecretString: aws.String(v),
+		}, nil
+	}}
+}
+
+// newErrorStub returns a stubClient which fails every request with err.
+func newErrorStub(err error) *stubClient {
+	return &stubClient{get: func(*secretsmanager.GetSecretValueInput) (*secretsmanager.GetSecretValueOutput, error) {
+		return nil, err
+	}}
+}
+
+// useDefaultAPI makes the package-level functions read through api for the
+// rest of the test.
+func useDefaultAPI(t *testing.T, api SecretsManagerAPI) {
+	t.Helper()
+	defaultClientMu.Lock()
+	previous := defaultClient
+	defaultClient = NewClientWithAPI(api)
+	defaultClientMu.Unlock()
+
+	t.Cleanup(func() {
+		defaultClientMu.Lock()
+		defaultClient = previous
+		defaultClientMu.Unlock()
+	})
+}
+
+func testSession(t *testing.T) *session.Session {
+	t.Helper()
+	sess, err := session.NewSession(&aws.Config{
+		Region:      aws.String("us-east-1"),
+		Credentials: credentials.AnonymousCredentials,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sess
+}
+
+// smRequest is a request received by a fake Secrets Manager server.
+type smRequest struct {
+	Operation string
+	Host      string
+	Body      map[string]interface{}
+}
+
+// newSecretsManagerServer starts an HTTP server speaking the Secrets
+// Manager JSON protocol, for tests which exercise a real SDK client. Each
+// request is passed to respond, whose result is encoded as the response
+// body. Every request received is sent on the returned channel, which is
+// buffered generously so that tests needn't drain it.
+func newSecretsManagerServer(t *testing.T, respond func(smRequest) (status int, body interface{})) (url string, requests <-chan smRequest) {
+	t.Helper()
+	ch := make(chan smRequest, 100)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := smRequest{
+			Operation: strings.TrimPrefix(r.Header.Get("X-Amz-Target"), "secretsmanager."),
+			Host:      r.Host,
+		}
+		json.NewDecoder(r.Body).Decode(&req.Body)
+		ch <- req
+
+		status, body := respond(req)
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(body)
+	}))
+	t.Cleanup(server.Close)
+	return server.URL, ch
+}
+
+// newStringSecretsSession returns a session whose Secrets Manager endpoint
+// is a fake server answering GetSecretValue from values. Missing names get
+// a ResourceNotFoundException.
+func newStringSecretsSession(t *testing.T, values map[string]string) *session.Session {
+	t.Helper()
+	url, _ := newSecretsManagerServer(t, func(req smRequest) (int, interface{}) {
+		name, _ := req.Body["SecretId"].(string)
+		value, ok := values[name]
+		if !ok {
+			return http.StatusBadRequest, map[string]string{"__type": "ResourceNotFoundException", "message": "not found"}
+		}
+		return http.StatusOK, map[string]string{"Name": name, "SecretString": value}
+	})
+	return testSession(t).Copy(aws.NewConfig().WithEndpoint(url))
+}
@@ -0,0 +1,99 @@
+package awssecret
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/pkg/errors"
+)
+
+// GetTaggedSecret retrieves the named secret from AWS Secrets Manager and
+// decodes it, as a JSON object, into a new struct of type T, matching keys
+// to fields by the given struct tag rather than by json tags. For example,
+// with tag "env", a field tagged `env:"DB_HOST"` receives the secret's
+// DB_HOST key. This lets an existing env-tagged config struct be reused.
+//
+// Keys are matched exactly, falling back to a case-insensitive match. Only
+// the part of the tag before any comma is used, and fields without the tag
+// (or tagged "-") are left unchanged. A string value is also accepted for
+// a numeric or boolean field if its contents decode into it, since
+// env-style secrets often store every value as a string.
+//
+func GetTaggedSecret[T any](sess *session.Session, secretName, tag string) (v *T, err error) {
+	return GetTaggedSecretWithContext[T](context.Background(), sess, secretName, tag)
+}
+
+// GetTaggedSecretWithContext is the same as GetTaggedSecret, but uses the
+// supplied context for the underlying AWS request.
+//
+func GetTaggedSecretWithContext[T any](ctx context.Context, sess *session.Session, secretName, tag string) (v *T, err error) {
+	v = new(T)
+	secret, err := GetStringSecretWithContext(ctx, sess, secretName)
+	if err != nil {
+		return v, errors.Wrapf(err, "Couldn't decode secret. Failed to retrieve secret.")
+	}
+
+	err = decodeTagged([]byte(secret), v, tag)
+	if err != nil {
+		return v, errors.Wrapf(err, "Couldn't decode secret %q", secretName)
+	}
+	return v, nil
+}
+
+// decodeTagged decodes the JSON object data into the struct pointed to by
+// v, matching keys to fields by tag.
+//
+func decodeTagged(data []byte, v interface{}, tag string) error {
+	rv := reflect.ValueOf(v).Elem()
+	if rv.Kind() != reflect.Struct {
+		return errors.Errorf("%s is not a struct", rv.Type())
+	}
+
+	var obj map[string]json.RawMessage
+	err := Unmarshal(data, &obj)
+	if err != nil {
+		return errors.Wrapf(err, "Failed to decode JSON")
+	}
+
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get(tag), ",")
+		if !f.IsExported() || name == "" || name == "-" {
+			continue
+		}
+
+		raw, ok := lookupKey(obj, name)
+		if !ok {
+			continue
+		}
+
+		field := rv.Field(i).Addr().Interface()
+		err = Unmarshal(raw, field)
+		if err != nil {
+			// Retry with the contents of a quoted value, e.g. "5432".
+			var s string
+			if Unmarshal(raw, &s) != nil || Unmarshal([]byte(s), field) != nil {
+				return errors.Wrapf(err, "Failed to decode key %q into field %s", name, f.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// lookupKey returns obj[key], falling back to a case-insensitive match.
+//
+func lookupKey(obj map[string]json.RawMessage, key string) (json.RawMessage, bool) {
+	if raw, ok := obj[key]; ok {
+		return raw, true
+	}
+	for k, raw := range obj {
+		if strings.EqualFold(k, key) {
+			return raw, true
+		}
+	}
+	return nil, false
+}
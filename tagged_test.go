@@ -0,0 +1,83 @@
+package awssecret
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type envConfig struct {
+	Host     string        `env:"DB_HOST"`
+	Port     int           `env:"DB_PORT,required"`
+	TLS      bool          `env:"DB_TLS"`
+	Timeout  time.Duration `env:"DB_TIMEOUT"`
+	Password string        `env:"-"`
+	Region   string
+	Tags     []string `env:"DB_TAGS"`
+}
+
+func TestDecodeTagged(t *testing.T) {
+	defaults := envConfig{Host: "localhost", Port: 5432, Password: "unchanged", Region: "unchanged"}
+
+	tests := []struct {
+		name    string
+		data    string
+		want    envConfig
+		wantErr bool
+	}{
+		{"native types", `{"DB_HOST":"db.local","DB_PORT":6432,"DB_TLS":true,"DB_TIMEOUT":5000000000}`, envConfig{Host: "db.local", Port: 6432, TLS: true, Timeout: 5 * time.Second, Password: "unchanged", Region: "unchanged"}, false},
+		{"quoted values", `{"DB_PORT":"6432","DB_TLS":"true"}`, envConfig{Host: "localhost", Port: 6432, TLS: true, Password: "unchanged", Region: "unchanged"}, false},
+		{"case-insensitive keys", `{"db_host":"db.local"}`, envConfig{Host: "db.local", Port: 5432, Password: "unchanged", Region: "unchanged"}, false},
+		{"exact match wins", `{"db_host":"lower","DB_HOST":"exact"}`, envConfig{Host: "exact", Port: 5432, Password: "unchanged", Region: "unchanged"}, false},
+		{"untagged and ignored fields", `{"Password":"pw","Region":"us-east-1","-":"x"}`, defaults, false},
+		{"missing keys keep defaults", `{}`, defaults, false},
+		{"arrays", `{"DB_TAGS":["a","b"]}`, envConfig{Host: "localhost", Port: 5432, Password: "unchanged", Region: "unchanged", Tags: []string{"a", "b"}}, false},
+		{"quoted value of the wrong type", `{"DB_PORT":"five"}`, envConfig{}, true},
+		{"number for a string", `{"DB_HOST":5432}`, envConfig{}, true},
+		{"not an object", `["DB_HOST"]`, envConfig{}, true},
+		{"not json", `DB_HOST=db.local`, envConfig{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := defaults
+			err := decodeTagged([]byte(tt.data), &got, "env")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeTaggedRequiresStruct(t *testing.T) {
+	var m map[string]string
+	if err := decodeTagged([]byte(`{"a":"b"}`), &m, "env"); err == nil {
+		t.Error("expected an error for a non-struct destination")
+	}
+}
+
+func TestGetTaggedSecret(t *testing.T) {
+	useDefaultAPI(t, newStringStub(map[string]string{
+		"app": `{"DB_HOST":"db.local","DB_PORT":"6432","DB_TLS":"false"}`,
+		"bad": `{"DB_PORT":"five"}`,
+	}))
+
+	v, err := GetTaggedSecret[envConfig](nil, "app", "env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Host != "db.local" || v.Port != 6432 || v.TLS {
+		t.Errorf("got %+v", v)
+	}
+
+	for _, name := range []string{"bad", "missing"} {
+		if _, err := GetTaggedSecret[envConfig](nil, name, "env"); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
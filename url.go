@@ -0,0 +1,41 @@
+package awssecret
+
+import (
+	"net"
+	"net/url"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// URL builds a URL with the given scheme from the credential: its Username
+// and Password as the userinfo, Host and Port (if non-zero) as the host,
+// and "/" + DBName (if set) as the path. Escaping is handled by net/url.
+// An error is returned if scheme or Host is empty.
+//
+func (c Credential) URL(scheme string) (*url.URL, error) {
+	if scheme == "" {
+		return nil, errors.New("Couldn't build URL. Scheme is empty")
+	}
+	if c.Host == "" {
+		return nil, errors.New("Couldn't build URL. Credential has no host")
+	}
+
+	u := &url.URL{
+		Scheme: scheme,
+		Host:   c.Host,
+	}
+	if c.Port != 0 {
+		u.Host = net.JoinHostPort(c.Host, strconv.Itoa(c.Port))
+	}
+	if c.Username != "" {
+		u.User = url.UserPassword(c.Username, c.Password)
+		if c.Password == "" {
+			u.User = url.User(c.Username)
+		}
+	}
+	if c.DBName != "" {
+		u.Path = "/" + c.DBName
+	}
+	return u, nil
+}
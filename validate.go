@@ -0,0 +1,133 @@
+package awssecret
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/pkg/errors"
+)
+
+// CredentialField identifies fields of a Credential. Values can be combined
+// with | to describe which fields are required.
+//
+type CredentialField uint
+
+// Fields of a Credential which can be required.
+const (
+	CredentialHost CredentialField = 1 << iota
+	CredentialPort
+	CredentialKey
+	CredentialUsername
+	CredentialPassword
+	CredentialDBName
+)
+
+// Validate returns an error listing each of the required fields which is
+// empty (or zero, for Port).
+//
+func (c *Credential) Validate(required CredentialField) error {
+	var missing []string
+	check := func(field CredentialField, name string, empty bool) {
+		if required&field != 0 && empty {
+			missing = append(missing, name)
+		}
+	}
+	check(CredentialHost, "host", c.Host == "")
+	check(CredentialPort, "port", c.Port == 0)
+	check(CredentialKey, "key", c.Key == "")
+	check(CredentialUsername, "username", c.Username == "")
+	check(CredentialPassword, "password", c.Password == "")
+	check(CredentialDBName, "dbname", c.DBName == "")
+
+	if len(missing) > 0 {
+		return errors.Errorf("Credential is missing required fields: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// GetValidatedCredentialSecret is the same as GetCredentialSecret, but also
+// returns an error if any of the required fields are empty.
+//
+func GetValidatedCredentialSecret(sess *session.Session, secretName string, required CredentialField) (cred *Credential, err error) {
+	return GetValidatedCredentialSecretWithContext(context.Background(), sess, secretName, required)
+}
+
+// GetValidatedCredentialSecretWithContext is the same as
+// GetValidatedCredentialSecret, but uses the supplied context for the
+// underlying AWS request.
+//
+func GetValidatedCredentialSecretWithContext(ctx context.Context, sess *session.Session, secretName string, required CredentialField) (cred *Credential, err error) {
+	cred, err = GetCredentialSecretWithContext(ctx, sess, secretName)
+	if err != nil {
+		return cred, err
+	}
+
+	err = cred.Validate(required)
+	if err != nil {
+		return cred, errors.Wrapf(err, "Invalid credential in secret %s", secretName)
+	}
+
+	return cred, nil
+}
+
+// APICredentialField identifies fields of an APICredential. Values can be
+// combined with | to describe which fields are required.
+//
+type APICredentialField uint
+
+// Fields of an APICredential which can be required.
+const (
+	APICredentialBaseURL APICredentialField = 1 << iota
+	APICredentialKey
+	APICredentialSecret
+
+	// APICredentialAll requires every field.
+	APICredentialAll = APICredentialBaseURL | APICredentialKey | APICredentialSecret
+)
+
+// Validate returns an error listing each of the required fields which is
+// empty.
+//
+func (c *APICredential) Validate(required APICredentialField) error {
+	var missing []string
+	check := func(field APICredentialField, name string, empty bool) {
+		if required&field != 0 && empty {
+			missing = append(missing, name)
+		}
+	}
+	check(APICredentialBaseURL, "baseURL", c.BaseURL == "")
+	check(APICredentialKey, "key", c.APIKey == "")
+	check(APICredentialSecret, "secret", c.APISecret == "")
+
+	if len(missing) > 0 {
+		return errors.Errorf("API credential is missing required fields: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// GetValidatedAPICredentialSecret is the same as GetAPICredentialSecret, but
+// also returns an error if any of the required fields are empty. Pass
+// APICredentialAll to require all of them.
+//
+func GetValidatedAPICredentialSecret(sess *session.Session, secretName string, required APICredentialField) (cred *APICredential, err error) {
+	return GetValidatedAPICredentialSecretWithContext(context.Background(), sess, secretName, required)
+}
+
+// GetValidatedAPICredentialSecretWithContext is the same as
+// GetValidatedAPICredentialSecret, but uses the supplied context for the
+// underlying AWS request.
+//
+func GetValidatedAPICredentialSecretWithContext(ctx context.Context, sess *session.Session, secretName string, required APICredentialField) (cred *APICredential, err error) {
+	cred, err = GetAPICredentialSecretWithContext(ctx, sess, secretName)
+	if err != nil {
+		return cred, err
+	}
+
+	err = cred.Validate(required)
+	if err != nil {
+		return cred, errors.Wrapf(err, "Invalid API credential in secret %s", secretName)
+	}
+
+	return cred, nil
+}
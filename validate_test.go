@@ -0,0 +1,128 @@
+package awssecret
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCredentialValidate(t *testing.T) {
+	full := Credential{Host: "db.local", Port: 5432, Key: "k", Username: "admin", Password: "pw", DBName: "app"}
+
+	tests := []struct {
+		name     string
+		cred     Credential
+		required CredentialField
+		wantErr  string
+	}{
+		{"nothing required", Credential{}, 0, ""},
+		{"all present", full, CredentialHost | CredentialPort | CredentialKey | CredentialUsername | CredentialPassword | CredentialDBName, ""},
+		{"missing host", Credential{Username: "admin"}, CredentialHost | CredentialUsername, "host"},
+		{"zero port", Credential{Host: "db.local"}, CredentialPort, "port"},
+		{"several missing are listed in order", Credential{}, CredentialPassword | CredentialHost | CredentialDBName, "host, password, dbname"},
+		{"unrequired fields are ignored", Credential{Host: "db.local"}, CredentialHost, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cred.Validate(tt.required)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.HasSuffix(err.Error(), ": "+tt.wantErr) {
+				t.Errorf("err = %v, want one listing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetValidatedCredentialSecret(t *testing.T) {
+	sess := newStringSecretsSession(t, map[string]string{
+		"full":    `{"host":"db.local","username":"admin","password":"pw"}`,
+		"partial": `{"host":"db.local"}`,
+	})
+
+	tests := []struct {
+		secret  string
+		wantErr bool
+	}{
+		{"full", false},
+		{"partial", true},
+		{"missing", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.secret, func(t *testing.T) {
+			cred, err := GetValidatedCredentialSecret(sess, tt.secret, CredentialHost|CredentialUsername|CredentialPassword)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.secret == "partial" && (cred == nil || cred.Host != "db.local") {
+				t.Errorf("the decoded credential should be returned with the error, got %+v", cred)
+			}
+		})
+	}
+}
+
+func TestAPICredentialValidate(t *testing.T) {
+	full := APICredential{BaseURL: "https://api.example.com", APIKey: "k", APISecret: "s"}
+
+	tests := []struct {
+		name     string
+		cred     APICredential
+		required APICredentialField
+		wantErr  string
+	}{
+		{"nothing required", APICredential{}, 0, ""},
+		{"all present", full, APICredentialAll, ""},
+		{"missing secret", APICredential{BaseURL: "https://api.example.com", APIKey: "k"}, APICredentialAll, "secret"},
+		{"all missing are listed in order", APICredential{}, APICredentialAll, "baseURL, key, secret"},
+		{"unrequired fields are ignored", APICredential{APIKey: "k"}, APICredentialKey, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cred.Validate(tt.required)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.HasSuffix(err.Error(), ": "+tt.wantErr) {
+				t.Errorf("err = %v, want one listing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetValidatedAPICredentialSecret(t *testing.T) {
+	useDefaultAPI(t, newStringStub(map[string]string{
+		"full":    `{"baseURL":"https://api.example.com","key":"k","secret":"s"}`,
+		"partial": `{"baseURL":"https://api.example.com"}`,
+	}))
+
+	tests := []struct {
+		secret  string
+		wantErr bool
+	}{
+		{"full", false},
+		{"partial", true},
+		{"missing", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.secret, func(t *testing.T) {
+			cred, err := GetValidatedAPICredentialSecret(nil, tt.secret, APICredentialAll)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.secret == "partial" {
+				if cred == nil || cred.BaseURL != "https://api.example.com" {
+					t.Errorf("cred = %+v, want the decoded credential alongside the error", cred)
+				}
+				if !strings.Contains(err.Error(), "key, secret") {
+					t.Errorf("err = %v, want it to list the missing fields", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package awssecret
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/pkg/errors"
+)
+
+// ListSecretVersionIdsAPI is the subset of the AWS Secrets Manager client
+// used to enumerate the versions of a secret.
+//
+type ListSecretVersionIdsAPI interface {
+	ListSecretVersionIdsWithContext(context.Context, *secretsmanager.ListSecretVersionIdsInput, ...request.Option) (*secretsmanager.ListSecretVersionIdsOutput, error)
+}
+
+// SecretVersion describes one version of a secret.
+//
+type SecretVersion struct {
+	VersionID     string
+	VersionStages []string
+	CreatedDate   time.Time
+}
+
+// ListSecretVersions returns the versions of the named secret which are
+// still attached to it, in the order AWS returns them. Only metadata is
+// read; no secret values are retrieved.
+//
+func ListSecretVersions(sess *session.Session, secretName string) (versions []SecretVersion, err error) {
+	return ListSecretVersionsWithContext(context.Background(), sess, secretName)
+}
+
+// ListSecretVersionsWithContext is the same as ListSecretVersions, but uses
+// the supplied context for the underlying AWS requests.
+//
+func ListSecretVersionsWithContext(ctx context.Context, sess *session.Session, secretName string) (versions []SecretVersion, err error) {
+	svc, err := newService(sess, ClientOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return ListSecretVersionsWithClientContext(ctx, svc, secretName)
+}
+
+// ListSecretVersionsWithClientContext is the same as
+// ListSecretVersionsWithContext, but uses the supplied client.
+//
+func ListSecretVersionsWithClientContext(ctx context.Context, client ListSecretVersionIdsAPI, secretName string) (versions []SecretVersion, err error) {
+	err = validateSecretID(secretName)
+	if err != nil {
+		return nil, err
+	}
+
+	input := &secretsmanager.ListSecretVersionIdsInput{
+		SecretId: aws.String(secretName),
+	}
+	for {
+		output, err := client.ListSecretVersionIdsWithContext(ctx, input)
+		if err != nil {
+			return nil, errors.Wrapf(mapAWSError(secretName, err), "Failed to list versions of secret %q in AWS Secrets Manager", secretName)
+		}
+
+		for _, entry := range output.Versions {
+			versions = append(versions, SecretVersion{
+				VersionID:     aws.StringValue(entry.VersionId),
+				VersionStages: aws.StringValueSlice(entry.VersionStages),
+				CreatedDate:   aws.TimeValue(entry.CreatedDate),
+			})
+		}
+
+		if aws.StringValue(output.NextToken) == "" {
+			return versions, nil
+		}
+		input.NextToken = output.NextToken
+	}
+}
@@ -0,0 +1,124 @@
+package awssecret
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+)
+
+// versionsStub is a hand-rolled ListSecretVersionIdsAPI which returns the
+// pages for a secret in order, linked by NextToken. Other secrets get a
+// ResourceNotFoundException.
+type versionsStub struct {
+	secret string
+	pages  [][]*secretsmanager.SecretVersionsListEntry
+	calls  int
+}
+
+func (s *versionsStub) ListSecretVersionIdsWithContext(ctx context.Context, input *secretsmanager.ListSecretVersionIdsInput, opts ...request.Option) (*secretsmanager.ListSecretVersionIdsOutput, error) {
+	s.calls++
+	if aws.StringValue(input.SecretId) != s.secret {
+		return nil, awserr.New(secretsmanager.ErrCodeResourceNotFoundException, "not found", nil)
+	}
+
+	page := 0
+	if input.NextToken != nil {
+		page, _ = strconv.Atoi(*input.NextToken)
+	}
+	output := &secretsmanager.ListSecretVersionIdsOutput{Versions: s.pages[page]}
+	if page+1 < len(s.pages) {
+		output.NextToken = aws.String(strconv.Itoa(page + 1))
+	}
+	return output, nil
+}
+
+func versionEntry(id string, created time.Time, stages ...string) *secretsmanager.SecretVersionsListEntry {
+	return &secretsmanager.SecretVersionsListEntry{
+		VersionId:     aws.String(id),
+		VersionStages: aws.StringSlice(stages),
+		CreatedDate:   aws.Time(created),
+	}
+}
+
+func TestListSecretVersions(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2021, 1, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name      string
+		pages     [][]*secretsmanager.SecretVersionsListEntry
+		want      []SecretVersion
+		wantCalls int
+	}{
+		{
+			"one page",
+			[][]*secretsmanager.SecretVersionsListEntry{
+				{versionEntry("v2", day(2), "AWSCURRENT"), versionEntry("v1", day(1), "AWSPREVIOUS")},
+			},
+			[]SecretVersion{
+				{VersionID: "v2", VersionStages: []string{"AWSCURRENT"}, CreatedDate: day(2)},
+				{VersionID: "v1", VersionStages: []string{"AWSPREVIOUS"}, CreatedDate: day(1)},
+			},
+			1,
+		},
+		{
+			"several pages",
+			[][]*secretsmanager.SecretVersionsListEntry{
+				{versionEntry("v3", day(3), "AWSPENDING")},
+				{},
+				{versionEntry("v2", day(2), "AWSCURRENT", "release-7"), versionEntry("v1", day(1))},
+			},
+			[]SecretVersion{
+				{VersionID: "v3", VersionStages: []string{"AWSPENDING"}, CreatedDate: day(3)},
+				{VersionID: "v2", VersionStages: []string{"AWSCURRENT", "release-7"}, CreatedDate: day(2)},
+				{VersionID: "v1", VersionStages: []string{}, CreatedDate: day(1)},
+			},
+			3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &versionsStub{secret: "db", pages: tt.pages}
+			got, err := ListSecretVersionsWithClientContext(context.Background(), stub, "db")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+			if stub.calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", stub.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestListSecretVersionsErrors(t *testing.T) {
+	tests := []struct {
+		secret    string
+		wantErr   error
+		wantCalls int
+	}{
+		{"missing", ErrSecretNotFound, 1},
+		{"arn:aws:secretsmanager:us-east-1", ErrMalformedARN, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.secret, func(t *testing.T) {
+			stub := &versionsStub{secret: "db"}
+			_, err := ListSecretVersionsWithClientContext(context.Background(), stub, tt.secret)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+			if stub.calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", stub.calls, tt.wantCalls)
+			}
+		})
+	}
+}
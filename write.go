@@ -0,0 +1,154 @@
+package awssecret
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/pkg/errors"
+)
+
+// SecretsManagerWriteAPI is the subset of the AWS Secrets Manager client
+// used to store secret values.
+//
+type SecretsManagerWriteAPI interface {
+	CreateSecretWithContext(context.Context, *secretsmanager.CreateSecretInput, ...request.Option) (*secretsmanager.CreateSecretOutput, error)
+	PutSecretValueWithContext(context.Context, *secretsmanager.PutSecretValueInput, ...request.Option) (*secretsmanager.PutSecretValueOutput, error)
+}
+
+// WriteStringSecret stores value as the current version of the named secret
+// in AWS Secrets Manager, creating the secret if it doesn't exist yet. The
+// VersionId of the new version is returned.
+//
+func WriteStringSecret(sess *session.Session, secretName, value string) (versionID string, err error) {
+	return WriteStringSecretWithContext(context.Background(), sess, secretName, value)
+}
+
+// WriteStringSecretWithContext is the same as WriteStringSecret, but uses
+// the supplied context for the underlying AWS requests.
+//
+func WriteStringSecretWithContext(ctx context.Context, sess *session.Session, secretName, value string) (versionID string, err error) {
+	svc, err := newService(sess, ClientOptions{})
+	if err != nil {
+		return "", err
+	}
+	return WriteStringSecretWithClientContext(ctx, svc, secretName, value)
+}
+
+// WriteStringSecretWithClientContext is the same as
+// WriteStringSecretWithContext, but uses the supplied client.
+//
+func WriteStringSecretWithClientContext(ctx context.Context, client SecretsManagerWriteAPI, secretName, value string) (versionID string, err error) {
+	versionID, err = putStringSecret(ctx, client, secretName, value)
+	if !isAWSErrorCode(err, secretsmanager.ErrCodeResourceNotFoundException) {
+		return versionID, err
+	}
+
+	created, err := client.CreateSecretWithContext(ctx, &secretsmanager.CreateSecretInput{
+		Name:         aws.String(secretName),
+		SecretString: aws.String(value),
+	})
+	if isAWSErrorCode(err, secretsmanager.ErrCodeResourceExistsException) {
+		// Another writer created the secret since the first attempt.
+		return putStringSecret(ctx, client, secretName, value)
+	}
+	if err != nil {
+		return "", errors.Wrapf(mapAWSError(secretName, err), "Failed to create secret %q in AWS Secrets Manager", secretName)
+	}
+
+	return aws.StringValue(created.VersionId), nil
+}
+
+func putStringSecret(ctx context.Context, client SecretsManagerWriteAPI, secretName, value string) (versionID string, err error) {
+	result, err := client.PutSecretValueWithContext(ctx, &secretsmanager.PutSecretValueInput{
+		SecretId:     aws.String(secretName),
+		SecretString: aws.String(value),
+	})
+	if isAWSErrorCode(err, secretsmanager.ErrCodeResourceNotFoundException) {
+		return "", err
+	}
+	if err != nil {
+		return "", errors.Wrapf(mapAWSError(secretName, err), "Failed to put secret %q in AWS Secrets Manager", secretName)
+	}
+
+	return aws.StringValue(result.VersionId), nil
+}
+
+// PutJSONSecret marshals v to JSON and stores it as a new version of the
+// named secret, which must already exist. Each call uses a fresh
+// ClientRequestToken, so it always creates a new version. If versionStages
+// are supplied (e.g. "AWSPENDING" during rotation) the new version is given
+// those labels instead of AWSCURRENT. The VersionId of the new version is
+// returned.
+//
+func PutJSONSecret(sess *session.Session, secretName string, v interface{}, versionStages ...string) (versionID string, err error) {
+	return PutJSONSecretWithContext(context.Background(), sess, secretName, v, versionStages...)
+}
+
+// PutJSONSecretWithContext is the same as PutJSONSecret, but uses the
+// supplied context for the underlying AWS request.
+//
+func PutJSONSecretWithContext(ctx context.Context, sess *session.Session, secretName string, v interface{}, versionStages ...string) (versionID string, err error) {
+	svc, err := newService(sess, ClientOptions{})
+	if err != nil {
+		return "", err
+	}
+	return PutJSONSecretWithClientContext(ctx, svc, secretName, v, versionStages...)
+}
+
+// PutJSONSecretWithClientContext is the same as PutJSONSecretWithContext,
+// but uses the supplied client.
+//
+func PutJSONSecretWithClientContext(ctx context.Context, client SecretsManagerWriteAPI, secretName string, v interface{}, versionStages ...string) (versionID string, err error) {
+	value, err := json.Marshal(v)
+	if err != nil {
+		return "", errors.Wrapf(err, "Couldn't marshal secret %q as JSON", secretName)
+	}
+
+	token, err := newClientRequestToken()
+	if err != nil {
+		return "", err
+	}
+
+	input := &secretsmanager.PutSecretValueInput{
+		SecretId:           aws.String(secretName),
+		SecretString:       aws.String(string(value)),
+		ClientRequestToken: aws.String(token),
+	}
+	if len(versionStages) > 0 {
+		input.VersionStages = aws.StringSlice(versionStages)
+	}
+
+	result, err := client.PutSecretValueWithContext(ctx, input)
+	if err != nil {
+		return "", errors.Wrapf(mapAWSError(secretName, err), "Failed to put secret %q in AWS Secrets Manager", secretName)
+	}
+
+	return aws.StringValue(result.VersionId), nil
+}
+
+// newClientRequestToken returns a random version 4 UUID, the format the AWS
+// SDK itself uses for ClientRequestToken.
+//
+func newClientRequestToken() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", errors.Wrap(err, "Couldn't generate ClientRequestToken")
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
+
+// isAWSErrorCode reports whether err is an awserr.Error with the given code.
+//
+func isAWSErrorCode(err error, code string) bool {
+	aerr, ok := err.(awserr.Error)
+	return ok && aerr.Code() == code
+}
@@ -0,0 +1,166 @@
+package awssecret
+
+import (
+	"context"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+)
+
+// writeStub records the write calls made to it. Secrets in exists accept
+// PutSecretValue; others fail with ResourceNotFoundException. If
+// createdElsewhere is set, CreateSecret reports that the secret already
+// exists and then lets subsequent puts succeed, as though another writer
+// had won the race.
+type writeStub struct {
+	exists           map[string]bool
+	createdElsewhere bool
+	createErr        error
+	ops              []string
+	puts             []*secretsmanager.PutSecretValueInput
+}
+
+func (s *writeStub) CreateSecretWithContext(ctx context.Context, input *secretsmanager.CreateSecretInput, _ ...request.Option) (*secretsmanager.CreateSecretOutput, error) {
+	s.ops = append(s.ops, "create")
+	if s.createErr != nil {
+		return nil, s.createErr
+	}
+	if s.createdElsewhere {
+		s.exists[aws.StringValue(input.Name)] = true
+		return nil, awserr.New(secretsmanager.ErrCodeResourceExistsException, "exists", nil)
+	}
+	s.exists[aws.StringValue(input.Name)] = true
+	return &secretsmanager.CreateSecretOutput{Name: input.Name, VersionId: aws.String("created")}, nil
+}
+
+func (s *writeStub) PutSecretValueWithContext(ctx context.Context, input *secretsmanager.PutSecretValueInput, _ ...request.Option) (*secretsmanager.PutSecretValueOutput, error) {
+	s.ops = append(s.ops, "put")
+	s.puts = append(s.puts, input)
+	if !s.exists[aws.StringValue(input.SecretId)] {
+		return nil, awserr.New(secretsmanager.ErrCodeResourceNotFoundException, "not found", nil)
+	}
+	return &secretsmanager.PutSecretValueOutput{Name: input.SecretId, VersionId: aws.String("put")}, nil
+}
+
+func TestWriteStringSecretWithClientContext(t *testing.T) {
+	tests := []struct {
+		name        string
+		stub        *writeStub
+		wantVersion string
+		wantOps     []string
+		wantErr     bool
+	}{
+		{"existing secret", &writeStub{exists: map[string]bool{"db": true}}, "put", []string{"put"}, false},
+		{"new secret", &writeStub{exists: map[string]bool{}}, "created", []string{"put", "create"}, false},
+		{"created concurrently", &writeStub{exists: map[string]bool{}, createdElsewhere: true}, "put", []string{"put", "create", "put"}, false},
+		{"create fails", &writeStub{exists: map[string]bool{}, createErr: awserr.New("AccessDeniedException", "no", nil)}, "", []string{"put", "create"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := WriteStringSecretWithClientContext(context.Background(), tt.stub, "db", "hunter2")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.wantVersion {
+				t.Errorf("version = %q, want %q", got, tt.wantVersion)
+			}
+			if len(tt.stub.ops) != len(tt.wantOps) {
+				t.Fatalf("ops = %v, want %v", tt.stub.ops, tt.wantOps)
+			}
+			for i := range tt.wantOps {
+				if tt.stub.ops[i] != tt.wantOps[i] {
+					t.Errorf("ops = %v, want %v", tt.stub.ops, tt.wantOps)
+					break
+				}
+			}
+			for _, put := range tt.stub.puts {
+				if aws.StringValue(put.SecretString) != "hunter2" {
+					t.Errorf("put %q, want hunter2", aws.StringValue(put.SecretString))
+				}
+			}
+		})
+	}
+}
+
+func TestPutJSONSecretWithClientContext(t *testing.T) {
+	cred := Credential{Host: "db.local", Username: "admin", Password: "n3w-pa55"}
+
+	tests := []struct {
+		name       string
+		secret     string
+		value      interface{}
+		stages     []string
+		wantString string
+		wantErr    bool
+		wantPut    bool
+	}{
+		{"credential", "db", cred, nil, `{"engine":"","host":"db.local","port":0,"key":"","username":"admin","password":"n3w-pa55","dbname":""}`, false, true},
+		{"pending", "db", map[string]string{"password": "n3w"}, []string{"AWSPENDING"}, `{"password":"n3w"}`, false, true},
+		{"several stages", "db", []int{1, 2}, []string{"AWSPENDING", "custom"}, `[1,2]`, false, true},
+		{"missing secret", "missing", cred, nil, "", true, true},
+		{"unmarshalable", "db", func() {}, nil, "", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &writeStub{exists: map[string]bool{"db": true}}
+			got, err := PutJSONSecretWithClientContext(context.Background(), stub, tt.secret, tt.value, tt.stages...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantPut {
+				if len(stub.puts) != 0 {
+					t.Errorf("PutSecretValue called %d times, want 0", len(stub.puts))
+				}
+				return
+			}
+			if len(stub.puts) != 1 {
+				t.Fatalf("PutSecretValue called %d times, want 1", len(stub.puts))
+			}
+			if tt.wantErr {
+				return
+			}
+			if got != "put" {
+				t.Errorf("version = %q, want put", got)
+			}
+
+			put := stub.puts[0]
+			if s := aws.StringValue(put.SecretString); s != tt.wantString {
+				t.Errorf("SecretString = %s, want %s", s, tt.wantString)
+			}
+			if tt.stages == nil && put.VersionStages != nil {
+				t.Errorf("VersionStages = %v, want them unset", aws.StringValueSlice(put.VersionStages))
+			}
+			if stages := aws.StringValueSlice(put.VersionStages); tt.stages != nil && !reflect.DeepEqual(stages, tt.stages) {
+				t.Errorf("VersionStages = %v, want %v", stages, tt.stages)
+			}
+			if put.ClientRequestToken == nil {
+				t.Error("ClientRequestToken isn't set")
+			}
+		})
+	}
+}
+
+func TestPutJSONSecretUsesFreshTokens(t *testing.T) {
+	stub := &writeStub{exists: map[string]bool{"db": true}}
+	for i := 0; i < 2; i++ {
+		if _, err := PutJSONSecretWithClientContext(context.Background(), stub, "db", map[string]int{"v": 1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	first, second := aws.StringValue(stub.puts[0].ClientRequestToken), aws.StringValue(stub.puts[1].ClientRequestToken)
+	if first == second {
+		t.Errorf("both puts used token %q", first)
+	}
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	for _, token := range []string{first, second} {
+		if !uuid.MatchString(token) {
+			t.Errorf("token %q isn't a version 4 UUID", token)
+		}
+	}
+}